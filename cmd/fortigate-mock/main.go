@@ -0,0 +1,125 @@
+// Copyright 2025 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Mock FortiGate REST API serving the jsonnet test fixtures
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"flag"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/prometheus-community/fortigate_exporter/internal/mock"
+	"github.com/prometheus/common/promslog"
+)
+
+var (
+	listen    = flag.String("listen", ":8443", "address to listen on")
+	fixtures  = flag.String("fixtures", "pkg/probe/testdata", "comma-separated directories with jsonnet fixtures, later directories override earlier ones")
+	profile   = flag.String("profile", "", "FortiOS version profile to serve, overlays <first fixtures dir>/versions/<profile> (e.g. 7.4)")
+	token     = flag.String("token", "", "API token clients have to present, any token is accepted if empty")
+	latency   = flag.Duration("latency", 0, "latency added to every response")
+	jitter    = flag.Duration("jitter", 0, "random latency of up to this duration added on top of -latency")
+	errorRate = flag.Float64("error-rate", 0, "ratio of requests to fail with -error-code")
+	errorCode = flag.Int("error-code", http.StatusInternalServerError, "HTTP status code returned for injected errors")
+	tlsCert   = flag.String("tls-cert", "", "PEM certificate to serve, a self-signed one is generated if empty")
+	tlsKey    = flag.String("tls-key", "", "PEM key for -tls-cert")
+)
+
+func selfSignedCert() (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "fortigate-mock"},
+		DNSNames:     []string{"localhost", "fortigate-mock"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(365 * 24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
+
+func main() {
+	level := promslog.NewLevel()
+	flag.Var(level, "log.level", "Only log messages with the given severity or above. One of: [debug, info, warn, error]")
+	flag.Parse()
+	slog.SetDefault(promslog.New(&promslog.Config{Level: level}))
+
+	dirs := strings.Split(*fixtures, ",")
+	if *profile != "" {
+		dirs = append(dirs, filepath.Join(dirs[0], "versions", *profile))
+	}
+	fs := mock.Fixtures{}
+	for _, dir := range dirs {
+		if _, err := os.Stat(dir); err != nil {
+			slog.Error("Fixture directory not found", "dir", dir, "err", err)
+			os.Exit(1)
+		}
+		if err := fs.Load(dir); err != nil {
+			slog.Error("Failed to load fixtures", "dir", dir, "err", err)
+			os.Exit(1)
+		}
+	}
+	slog.Info("Loaded fixtures", "paths", len(fs), "profile", *profile)
+
+	var cert tls.Certificate
+	var err error
+	if *tlsCert != "" {
+		cert, err = tls.LoadX509KeyPair(*tlsCert, *tlsKey)
+	} else {
+		cert, err = selfSignedCert()
+	}
+	if err != nil {
+		slog.Error("Unable to set up TLS certificate", "err", err)
+		os.Exit(1)
+	}
+
+	srv := &http.Server{
+		Addr: *listen,
+		Handler: &mock.Handler{
+			Fixtures: fs,
+			Options: mock.Options{
+				Token:     *token,
+				Latency:   *latency,
+				Jitter:    *jitter,
+				ErrorRate: *errorRate,
+				ErrorCode: *errorCode,
+			},
+		},
+		TLSConfig: &tls.Config{Certificates: []tls.Certificate{cert}},
+	}
+	slog.Info("FortiGate mock running", "listen", *listen)
+	if err := srv.ListenAndServeTLS("", ""); err != nil {
+		slog.Error("Unable to serve", "err", err)
+		os.Exit(1)
+	}
+}
@@ -31,15 +31,23 @@
 package main
 
 import (
-	"log"
+	"context"
+	"flag"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
 	"runtime"
 	"runtime/debug"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/prometheus-community/fortigate_exporter/pkg/probe"
 
 	"github.com/prometheus-community/fortigate_exporter/internal/config"
+	"github.com/prometheus-community/fortigate_exporter/internal/push"
+	"github.com/prometheus-community/fortigate_exporter/internal/tracing"
 	fortiHTTP "github.com/prometheus-community/fortigate_exporter/pkg/http"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
@@ -90,27 +98,61 @@ func getBuildInfo() BuildInfo {
 }
 
 func main() {
-	buildInfo := getBuildInfo()
-	log.Printf("FortigateExporter %s ( %s )", buildInfo.version, buildInfo.gitHash)
-	setUpMetricsEndpoint(buildInfo)
-
 	if err := config.Init(); err != nil {
-		log.Fatalf("Initialization error: %+v", err)
+		slog.Error("Initialization error", "err", err)
+		os.Exit(1)
 	}
 
+	buildInfo := getBuildInfo()
+	slog.Info("Starting FortigateExporter", "version", buildInfo.version, "revision", buildInfo.gitHash)
+	setUpMetricsEndpoint(buildInfo)
+
 	savedConfig := config.GetConfig()
 
 	if err := fortiHTTP.Configure(savedConfig); err != nil {
-		log.Fatalf("%+v", err)
+		slog.Error("Unable to configure HTTP client", "err", err)
+		os.Exit(1)
+	}
+
+	// One-shot mode: fortigate_exporter [flags] probe -target https://my-fortigate
+	if flag.Arg(0) == "probe" {
+		os.Exit(probeCommand(flag.Args()[1:], savedConfig, os.Stdout, os.Stderr))
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), buildInfo.version)
+	if err != nil {
+		slog.Error("Unable to set up tracing", "err", err)
+		os.Exit(1)
 	}
 
 	http.Handle("/metrics", promhttp.Handler())
 	http.HandleFunc("/probe", probe.ProbeHandler)
 	go func() {
 		if err := http.ListenAndServe(savedConfig.Listen, nil); err != nil {
-			log.Fatalf("Unable to serve: %v", err)
+			slog.Error("Unable to serve", "err", err)
+			os.Exit(1)
 		}
 	}()
-	log.Printf("Fortigate exporter running, listening on %q", savedConfig.Listen)
-	select {}
+	slog.Info("Fortigate exporter running", "listen", savedConfig.Listen)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if savedConfig.PushURL != "" {
+		pusher, err := push.New(savedConfig, buildInfo.version)
+		if err != nil {
+			slog.Error("Unable to set up pushing metrics", "err", err)
+			os.Exit(1)
+		}
+		go pusher.Run(ctx)
+	}
+
+	<-ctx.Done()
+	slog.Info("Shutting down")
+	// Flush pending spans before exiting
+	sctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownTracing(sctx); err != nil {
+		slog.Warn("Failed to flush traces", "err", err)
+	}
 }
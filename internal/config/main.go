@@ -15,10 +15,14 @@ package config
 
 import (
 	"flag"
-	"log"
+	"fmt"
+	"log/slog"
+	"net/url"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/prometheus/common/promslog"
 	"gopkg.in/yaml.v2"
 )
 
@@ -31,6 +35,22 @@ type FortiExporterParameter struct {
 	TlsExtraCAs   *string
 	MaxBGPPaths   *int
 	MaxVPNUsers   *int
+	MaxAdmins     *int
+	MaxBannedIPs  *int
+	MaxDHCPLeases *int
+	NeighborFlaps *bool
+	MaxRequests   *int
+	MaxSeries     *int
+	ResInterval   *string
+	IdleTimeout   *int
+	LogLevel      *promslog.Level
+	LogFormat     *promslog.Format
+	RecordDir     *string
+	RecordRedact  *bool
+	PushURL       *string
+	PushProtocol  *string
+	PushInterval  *int
+	PushQueueSize *int
 }
 
 type FortiExporterConfig struct {
@@ -42,10 +62,35 @@ type FortiExporterConfig struct {
 	TlsExtraCAs   []LocalCert
 	MaxBGPPaths   int
 	MaxVPNUsers   int
+	MaxAdmins     int
+	MaxBannedIPs  int
+	MaxDHCPLeases int
+	NeighborFlaps bool
+	MaxRequests   int
+	MaxSeries     int
+	ResInterval   string
+	IdleTimeout   int
+	RecordDir     string
+	RecordRedact  bool
+	PushURL       string
+	PushProtocol  string
+	PushInterval  int
+	PushQueueSize int
 }
 
 type AuthKeys map[Target]TargetAuth
 
+// With returns a copy of k with auth set for target. The loaded keys are
+// shared by all requests and must not be modified.
+func (k AuthKeys) With(target Target, auth TargetAuth) AuthKeys {
+	keys := make(AuthKeys, len(k)+1)
+	for t, a := range k {
+		keys[t] = a
+	}
+	keys[target] = auth
+	return keys
+}
+
 type Target string
 type Token string
 type ProbeList []string
@@ -58,6 +103,60 @@ type Probes struct {
 type TargetAuth struct {
 	Token  Token
 	Probes Probes
+	// MaxRequests overrides the -max-requests-per-target flag for this target when set
+	MaxRequests int `yaml:"max_requests"`
+	// ProxyURL is an http://, https:// or socks5:// proxy used to reach the target
+	ProxyURL string `yaml:"proxy_url"`
+	// Labels are added to every metric of the target
+	Labels map[string]string `yaml:"labels"`
+	// LabelPrefix is put in front of a label that collides with a label of a metric, target_ if empty
+	LabelPrefix string `yaml:"label_prefix"`
+	// MetricRelabelConfigs are applied to the metrics of every probe
+	MetricRelabelConfigs []RelabelConfig `yaml:"metric_relabel_configs"`
+	// SeriesLimits caps the number of series of the probes matching the prefix
+	SeriesLimits map[string]int `yaml:"series_limits"`
+	// StaleRetention is how long the last good values of the probes matching
+	// the prefix are returned when they fail
+	StaleRetention map[string]time.Duration `yaml:"stale_retention"`
+}
+
+// SeriesLimit returns the series limit of probe, or def if none is set
+func (a TargetAuth) SeriesLimit(probe string, def int) int {
+	if l, ok := longestPrefix(a.SeriesLimits, probe); ok {
+		return l
+	}
+	return def
+}
+
+// DefaultSeriesLimit returns the series limit of probe for targets without a
+// series_limits entry for it. -max-vpn-users and -max-bgp-paths predate
+// series_limits and are kept as the defaults of their probes.
+func (c FortiExporterConfig) DefaultSeriesLimit(probe string) int {
+	switch {
+	case strings.HasPrefix(probe, "VPN/Ssl/Connections") && c.MaxVPNUsers > 0:
+		return c.MaxVPNUsers
+	case strings.HasPrefix(probe, "BGP/NeighborPaths") && c.MaxBGPPaths > 0:
+		return c.MaxBGPPaths
+	}
+	return c.MaxSeries
+}
+
+// StaleRetentionOf returns how long the last good values of probe are kept, 0 if they are not
+func (a TargetAuth) StaleRetentionOf(probe string) time.Duration {
+	d, _ := longestPrefix(a.StaleRetention, probe)
+	return d
+}
+
+// longestPrefix returns the value of the longest key of m that probe starts with
+func longestPrefix[V any](m map[string]V, probe string) (V, bool) {
+	var v V
+	match := -1
+	for prefix, pv := range m {
+		if strings.HasPrefix(probe, prefix) && len(prefix) > match {
+			v, match = pv, len(prefix)
+		}
+	}
+	return v, match >= 0
 }
 
 type LocalCert struct {
@@ -73,13 +172,35 @@ var (
 		TLSTimeout:    flag.Int("https-timeout", 10, "TLS Handshake timeout in seconds"),
 		TLSInsecure:   flag.Bool("insecure", false, "Allow insecure certificates"),
 		TlsExtraCAs:   flag.String("extra-ca-certs", "", "comma-separated files containing extra PEMs to trust for TLS connections in addition to the system trust store"),
-		MaxBGPPaths:   flag.Int("max-bgp-paths", 10000, "How many BGP Paths to receive when counting routes, needs to be greater than or equal to the number of routes or metrics will not be generated"),
-		MaxVPNUsers:   flag.Int("max-vpn-users", 0, "How many VPN Users to receive when counting users, needs to be greater than or equal the number of users or metrics will not be generated (0 eq. none by default)"),
+		MaxBGPPaths:   flag.Int("max-bgp-paths", 10000, "How many BGP Paths to receive when counting routes, also the default series limit of BGP/NeighborPaths (0 disables the probe)"),
+		MaxVPNUsers:   flag.Int("max-vpn-users", 0, "Export the connections per VPN user, the value is the default series limit of VPN/Ssl/Connections (0 eq. none by default)"),
+		MaxAdmins:     flag.Int("max-admin-sessions", 0, "How many admin sessions to receive when exporting them per administrator, needs to be greater than or equal to their number or metrics will not be generated (0 eq. none by default)"),
+		MaxBannedIPs:  flag.Int("max-banned-ips", 0, "How many banned IPs to receive when exporting their expiry per IP, needs to be greater than or equal to their number or metrics will not be generated (0 eq. none by default)"),
+		MaxDHCPLeases: flag.Int("max-dhcp-leases", 0, "How many DHCP leases to receive when exporting them per lease, needs to be greater than or equal to the number of leases or metrics will not be generated (0 eq. none by default)"),
+		NeighborFlaps: flag.Bool("neighbor-flap-detection", false, "Count ARP and IPv6 neighbor entries changing their MAC address between scrapes, keeps the neighbor tables of all targets in memory"),
+		MaxRequests:   flag.Int("max-requests-per-target", 4, "How many API requests may be in flight at the same time towards a single target (0 eq. unlimited)"),
+		MaxSeries:     flag.Int("max-series-per-probe", 0, "How many series a probe may return before all of them are dropped and the probe fails, can be overridden per target with series_limits (0 eq. unlimited)"),
+		ResInterval:   flag.String("resource-usage-interval", "1-min", "window of the resource usage statistics, one of: [1-min, 10-min, 30-min, 1-hour, 12-hour, 24-hour]"),
+		IdleTimeout:   flag.Int("idle-conn-timeout", 90, "Seconds an idle keep-alive connection to a target is kept open before being closed"),
+		RecordDir:     flag.String("record-dir", "", "directory to write every API response to as test fixture, one subdirectory per target (disabled if empty)"),
+		RecordRedact:  flag.Bool("record-redact", false, "replace serial numbers, IP addresses, user names and secrets in recorded API responses"),
+		PushURL:       flag.String("push-url", "", "URL of an OTLP or Prometheus remote write endpoint to push the metrics of all targets in the auth file to (disabled if empty)"),
+		PushProtocol:  flag.String("push-protocol", "remote-write", "protocol used to push metrics, one of: [remote-write, otlp]"),
+		PushInterval:  flag.Int("push-interval", 60, "seconds between probes of a target when pushing metrics"),
+		PushQueueSize: flag.Int("push-queue-size", 100, "how many probe results are queued while the push endpoint is unreachable before the oldest are dropped"),
+		LogLevel:      promslog.NewLevel(),
+		LogFormat:     promslog.NewFormat(),
 	}
 
 	savedConfig *FortiExporterConfig
 )
 
+func init() {
+	_ = parameter.LogFormat.Set("logfmt")
+	flag.Var(parameter.LogLevel, "log.level", "Only log messages with the given severity or above. One of: [debug, info, warn, error]")
+	flag.Var(parameter.LogFormat, "log.format", "Output format of log messages. One of: [logfmt, json]")
+}
+
 func Init() error {
 	// check if already parsed
 	if savedConfig != nil {
@@ -90,12 +211,18 @@ func Init() error {
 
 func MustReInit() {
 	if err := ReInit(); err != nil {
-		log.Fatalf("config.ReInit failed: %+v", err)
+		slog.Error("config.ReInit failed", "err", err)
+		os.Exit(1)
 	}
 }
 func ReInit() error {
 	flag.Parse()
 
+	slog.SetDefault(promslog.New(&promslog.Config{
+		Level:  parameter.LogLevel,
+		Format: parameter.LogFormat,
+	}))
+
 	savedConfig = &FortiExporterConfig{
 		Listen:        *parameter.Listen,
 		ScrapeTimeout: *parameter.ScrapeTimeout,
@@ -103,21 +230,61 @@ func ReInit() error {
 		TLSInsecure:   *parameter.TLSInsecure,
 		MaxBGPPaths:   *parameter.MaxBGPPaths,
 		MaxVPNUsers:   *parameter.MaxVPNUsers,
+		MaxAdmins:     *parameter.MaxAdmins,
+		MaxBannedIPs:  *parameter.MaxBannedIPs,
+		MaxDHCPLeases: *parameter.MaxDHCPLeases,
+		NeighborFlaps: *parameter.NeighborFlaps,
+		MaxRequests:   *parameter.MaxRequests,
+		MaxSeries:     *parameter.MaxSeries,
+		ResInterval:   *parameter.ResInterval,
+		IdleTimeout:   *parameter.IdleTimeout,
+		RecordDir:     *parameter.RecordDir,
+		RecordRedact:  *parameter.RecordRedact,
+		PushURL:       *parameter.PushURL,
+		PushProtocol:  *parameter.PushProtocol,
+		PushInterval:  *parameter.PushInterval,
+		PushQueueSize: *parameter.PushQueueSize,
+	}
+
+	switch savedConfig.ResInterval {
+	case "1-min", "10-min", "30-min", "1-hour", "12-hour", "24-hour":
+	default:
+		return fmt.Errorf("invalid resource usage interval %q", savedConfig.ResInterval)
+	}
+
+	if savedConfig.PushURL != "" {
+		if err := validatePush(savedConfig); err != nil {
+			return fmt.Errorf("invalid push configuration: %w", err)
+		}
 	}
 
 	// parse AuthKeys
 	af, err := os.ReadFile(*parameter.AuthFile)
 	if err != nil {
-		log.Fatalf("Failed to read API authentication map file: %v", err)
-		return err
+		return fmt.Errorf("failed to read API authentication map file: %w", err)
 	}
 
 	if err := yaml.Unmarshal(af, &savedConfig.AuthKeys); err != nil {
-		log.Fatalf("Failed to parse API authentication map file: %v", err)
-		return err
+		return fmt.Errorf("failed to parse API authentication map file: %w", err)
 	}
 
-	log.Printf("Loaded %d API keys", len(savedConfig.AuthKeys))
+	for t, a := range savedConfig.AuthKeys {
+		if a.ProxyURL != "" {
+			if _, err := ParseProxyURL(a.ProxyURL); err != nil {
+				return fmt.Errorf("invalid proxy_url for %q: %w", t, err)
+			}
+		}
+		for l := range a.Labels {
+			if !validLabelName(l) {
+				return fmt.Errorf("invalid label name %q for %q", l, t)
+			}
+		}
+		if a.LabelPrefix != "" && !validLabelName(a.LabelPrefix) {
+			return fmt.Errorf("invalid label_prefix %q for %q", a.LabelPrefix, t)
+		}
+	}
+
+	slog.Info("Loaded API keys", "count", len(savedConfig.AuthKeys))
 
 	// parse ExtraCAs
 	for _, eca := range strings.Split(*parameter.TlsExtraCAs, ",") {
@@ -127,8 +294,7 @@ func ReInit() error {
 
 		certs, err := os.ReadFile(eca)
 		if err != nil {
-			log.Fatalf("Failed to read extra CA file %q: %v", eca, err)
-			return err
+			return fmt.Errorf("failed to read extra CA file %q: %w", eca, err)
 		}
 
 		certObject := LocalCert{
@@ -141,6 +307,59 @@ func ReInit() error {
 	return nil
 }
 
+// ParseProxyURL parses and validates a per-target proxy URL
+func ParseProxyURL(proxy string) (*url.URL, error) {
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("proxy URL %q has no host", proxy)
+	}
+	return u, nil
+}
+
+// validLabelName reports whether name can be used as a Prometheus label name
+// that is not reserved for internal use
+func validLabelName(name string) bool {
+	if name == "" || strings.HasPrefix(name, "__") {
+		return false
+	}
+	for i, r := range name {
+		if r != '_' && (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (i == 0 || r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+func validatePush(c *FortiExporterConfig) error {
+	u, err := url.Parse(c.PushURL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported push URL scheme %q", u.Scheme)
+	}
+	switch c.PushProtocol {
+	case "remote-write", "otlp":
+	default:
+		return fmt.Errorf("unknown push protocol %q", c.PushProtocol)
+	}
+	if c.PushInterval <= 0 {
+		return fmt.Errorf("push interval must be positive")
+	}
+	if c.PushQueueSize <= 0 {
+		return fmt.Errorf("push queue size must be positive")
+	}
+	return nil
+}
+
 func GetConfig() FortiExporterConfig {
 	return *savedConfig
 }
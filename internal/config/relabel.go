@@ -0,0 +1,80 @@
+// Copyright 2025 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Relabel actions supported in metric_relabel_configs
+const (
+	RelabelReplace   = "replace"
+	RelabelKeep      = "keep"
+	RelabelDrop      = "drop"
+	RelabelLabelDrop = "labeldrop"
+)
+
+// RelabelConfig is a rule applied to the metrics of a target, modelled after
+// metric_relabel_configs of Prometheus
+type RelabelConfig struct {
+	SourceLabels []string `yaml:"source_labels"`
+	Separator    string   `yaml:"separator"`
+	Regex        string   `yaml:"regex"`
+	TargetLabel  string   `yaml:"target_label"`
+	Replacement  string   `yaml:"replacement"`
+	Action       string   `yaml:"action"`
+
+	re *regexp.Regexp
+}
+
+// UnmarshalYAML applies the defaults of Prometheus and validates the rule
+func (r *RelabelConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain RelabelConfig
+	*r = RelabelConfig{
+		Separator:   ";",
+		Regex:       "(.*)",
+		Replacement: "$1",
+		Action:      RelabelReplace,
+	}
+	if err := unmarshal((*plain)(r)); err != nil {
+		return err
+	}
+	re, err := regexp.Compile("^(?:" + r.Regex + ")$")
+	if err != nil {
+		return fmt.Errorf("invalid regex %q: %w", r.Regex, err)
+	}
+	r.re = re
+	switch r.Action {
+	case RelabelReplace:
+		if r.TargetLabel == "" {
+			return fmt.Errorf("relabel action %q requires target_label", r.Action)
+		}
+	case RelabelKeep, RelabelDrop:
+		if len(r.SourceLabels) == 0 {
+			return fmt.Errorf("relabel action %q requires source_labels", r.Action)
+		}
+	case RelabelLabelDrop:
+	default:
+		return fmt.Errorf("unknown relabel action %q, must be one of %s", r.Action,
+			strings.Join([]string{RelabelReplace, RelabelKeep, RelabelDrop, RelabelLabelDrop}, ", "))
+	}
+	return nil
+}
+
+// Regexp returns the anchored regular expression of the rule
+func (r *RelabelConfig) Regexp() *regexp.Regexp {
+	return r.re
+}
@@ -0,0 +1,89 @@
+// Copyright 2025 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package exportertest points the exporter at a mock FortiGate in tests.
+package exportertest
+
+import (
+	"flag"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/prometheus-community/fortigate_exporter/internal/config"
+	"github.com/prometheus-community/fortigate_exporter/internal/mock"
+	fortiHTTP "github.com/prometheus-community/fortigate_exporter/pkg/http"
+)
+
+// testdata returns the fixture directory of the probes, wherever the test runs
+func testdata() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..", "pkg", "probe", "testdata")
+}
+
+// Fixtures loads the common fixtures with the ones of version on top
+func Fixtures(t testing.TB, version string) mock.Fixtures {
+	t.Helper()
+	fs := mock.Fixtures{}
+	for _, dir := range []string{testdata(), filepath.Join(testdata(), "versions", version)} {
+		if err := fs.Load(dir); err != nil {
+			t.Fatalf("failed to load fixtures: %v", err)
+		}
+	}
+	return fs
+}
+
+// Serve starts a mock FortiGate serving fs to clients using token
+func Serve(t testing.TB, fs mock.Fixtures, token string) *httptest.Server {
+	srv := httptest.NewTLSServer(&mock.Handler{Fixtures: fs, Options: mock.Options{Token: token}})
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// Configure writes auth to an auth file and reloads the global exporter
+// configuration with it and flags. The flags, the configuration and the
+// default logger, which discards everything meanwhile, are restored when the
+// test ends.
+func Configure(t testing.TB, auth string, flags map[string]string) {
+	t.Helper()
+	af := filepath.Join(t.TempDir(), "fortigate-key.yaml")
+	if err := os.WriteFile(af, []byte(auth), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// Cleanups run last-in first-out, so the flags are restored before this
+	t.Cleanup(func() { _ = config.ReInit() })
+	set := map[string]string{"auth-file": af, "insecure": "true", "log.level": "error"}
+	for k, v := range flags {
+		set[k] = v
+	}
+	for k, v := range set {
+		prev := flag.Lookup(k).Value.String()
+		if err := flag.Set(k, v); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { flag.Set(k, prev) })
+	}
+	if err := config.ReInit(); err != nil {
+		t.Fatal(err)
+	}
+	if err := fortiHTTP.Configure(config.GetConfig()); err != nil {
+		t.Fatal(err)
+	}
+	logger := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(logger) })
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
@@ -0,0 +1,211 @@
+// Copyright 2025 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mock serves jsonnet test fixtures as a fake FortiOS REST API.
+package mock
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-jsonnet"
+)
+
+// Fixture is a single prepared API response
+type Fixture struct {
+	Path  string
+	Query url.Values
+	File  string
+	Body  []byte
+}
+
+// matches reports whether every query parameter of the fixture is present in q
+func (f *Fixture) matches(q url.Values) bool {
+	for k, v := range f.Query {
+		if len(q[k]) == 0 || q[k][0] != v[0] {
+			return false
+		}
+	}
+	return true
+}
+
+// Fixtures maps an API path to the responses prepared for it
+type Fixtures map[string][]*Fixture
+
+// readHeader returns the "path?query" a fixture was recorded for, taken
+// from its first line, e.g. "# api/v2/monitor/system/status?vdom=root"
+func readHeader(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	if !s.Scan() {
+		return "", fmt.Errorf("%s: empty file", file)
+	}
+	line := strings.TrimSpace(s.Text())
+	if !strings.HasPrefix(line, "#") {
+		return "", fmt.Errorf("%s: first line is not a '# path?query' comment", file)
+	}
+	fields := strings.Fields(strings.TrimPrefix(line, "#"))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("%s: empty header comment", file)
+	}
+	return fields[0], nil
+}
+
+// Load reads all *.jsonnet fixtures in dir. Fixtures already present in fs
+// for the same path and query are replaced, which allows overlaying
+// version specific fixtures on top of a common set. If several fixtures in
+// dir have the same path and query, the one with the shortest file name is
+// served and the others are variants used by single tests, e.g.
+// ha-config-no-access.jsonnet next to ha-config.jsonnet.
+func (fs Fixtures) Load(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.jsonnet"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+	type entry struct {
+		file string
+		u    *url.URL
+	}
+	var keys []string
+	served := map[string]entry{}
+	for _, file := range files {
+		header, err := readHeader(file)
+		if err != nil {
+			return err
+		}
+		u, err := url.Parse(header)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		key := strings.Trim(u.Path, "/") + "?" + u.Query().Encode()
+		prev, ok := served[key]
+		switch {
+		case !ok:
+			keys = append(keys, key)
+		case len(filepath.Base(file)) == len(filepath.Base(prev.file)):
+			return fmt.Errorf("%s: same path and query as %s, cannot tell which one is the variant", file, prev.file)
+		case len(filepath.Base(file)) > len(filepath.Base(prev.file)):
+			slog.Debug("Not serving fixture variant", "file", file, "served", prev.file)
+			continue
+		default:
+			slog.Debug("Not serving fixture variant", "file", prev.file, "served", file)
+		}
+		served[key] = entry{file, u}
+	}
+
+	vm := jsonnet.MakeVM()
+	for _, key := range keys {
+		e := served[key]
+		out, err := vm.EvaluateFile(e.file)
+		if err != nil {
+			return fmt.Errorf("failed to evaluate jsonnet %q: %w", e.file, err)
+		}
+		path := strings.Trim(e.u.Path, "/")
+		fx := &Fixture{Path: path, Query: e.u.Query(), File: e.file, Body: []byte(out)}
+
+		kept := fs[path][:0]
+		for _, f := range fs[path] {
+			if f.Query.Encode() != fx.Query.Encode() {
+				kept = append(kept, f)
+			}
+		}
+		fs[path] = append(kept, fx)
+	}
+	return nil
+}
+
+// Lookup returns the fixture for path that matches the most query
+// parameters of q, or nil if there is none. On a tie the fixture loaded
+// last wins.
+func (fs Fixtures) Lookup(path string, q url.Values) *Fixture {
+	var best *Fixture
+	for _, f := range fs[strings.Trim(path, "/")] {
+		if f.matches(q) && (best == nil || len(f.Query) >= len(best.Query)) {
+			best = f
+		}
+	}
+	return best
+}
+
+// Options controls how the mock API behaves
+type Options struct {
+	// Token is the API token required as bearer token, no check if empty
+	Token string
+	// Latency is added to every response, plus a random share of up to Jitter
+	Latency time.Duration
+	Jitter  time.Duration
+	// ErrorRate is the ratio of requests answered with ErrorCode
+	ErrorRate float64
+	ErrorCode int
+}
+
+// Handler serves fixtures like the REST API of a FortiGate would
+type Handler struct {
+	Fixtures Fixtures
+	Options  Options
+}
+
+func (h *Handler) authorized(r *http.Request) bool {
+	if h.Options.Token == "" {
+		return true
+	}
+	if r.Header.Get("Authorization") == "Bearer "+h.Options.Token {
+		return true
+	}
+	return r.URL.Query().Get("access_token") == h.Options.Token
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(r) {
+		http.Error(w, `{"status":"error","http_status":401}`, http.StatusUnauthorized)
+		return
+	}
+	if d := h.Options.Latency + time.Duration(rand.Float64()*float64(h.Options.Jitter)); d > 0 {
+		select {
+		case <-time.After(d):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if h.Options.ErrorRate > 0 && rand.Float64() < h.Options.ErrorRate {
+		code := h.Options.ErrorCode
+		if code == 0 {
+			code = http.StatusInternalServerError
+		}
+		http.Error(w, fmt.Sprintf(`{"status":"error","http_status":%d}`, code), code)
+		return
+	}
+	f := h.Fixtures.Lookup(r.URL.Path, r.URL.Query())
+	if f == nil {
+		slog.Debug("No fixture for request", "path", r.URL.Path, "query", r.URL.RawQuery)
+		http.Error(w, `{"status":"error","http_status":404}`, http.StatusNotFound)
+		return
+	}
+	slog.Debug("Serving fixture", "path", r.URL.Path, "query", r.URL.RawQuery, "file", f.File)
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(f.Body)
+}
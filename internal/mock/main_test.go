@@ -0,0 +1,133 @@
+// Copyright 2025 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mock
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func loadTestdata(t *testing.T) Fixtures {
+	fs := Fixtures{}
+	if err := fs.Load("../../pkg/probe/testdata"); err != nil {
+		t.Fatalf("Load() error %v", err)
+	}
+	return fs
+}
+
+func TestLookup(t *testing.T) {
+	fs := loadTestdata(t)
+	for _, tc := range []struct {
+		path  string
+		query string
+		found bool
+	}{
+		{"api/v2/monitor/system/status", "", true},
+		{"/api/v2/monitor/system/time", "vdom=root", true},
+		{"api/v2/monitor/firewall/policy/select", "vdom=*&ip_version=ipv6", true},
+		{"api/v2/monitor/system/available-certificates", "scope=global", true},
+		{"api/v2/monitor/does-not-exist", "", false},
+	} {
+		q, _ := url.ParseQuery(tc.query)
+		if f := fs.Lookup(tc.path, q); (f != nil) != tc.found {
+			t.Errorf("Lookup(%q, %q) = %v, expected found %v", tc.path, tc.query, f, tc.found)
+		}
+	}
+
+	// The most specific fixture wins
+	q, _ := url.ParseQuery("vdom=*&ip_version=ipv6")
+	if f := fs.Lookup("api/v2/monitor/firewall/policy/select", q); f.Query.Get("ip_version") != "ipv6" {
+		t.Errorf("Lookup() returned %q, expected the ipv6 fixture", f.File)
+	}
+}
+
+func TestLoadVariants(t *testing.T) {
+	dir := t.TempDir()
+	for name, body := range map[string]string{
+		"ha-config.jsonnet":           `{"variant": false}`,
+		"ha-config-no-access.jsonnet": `{"variant": true}`,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("# api/v2/cmdb/system/ha\n"+body+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fs := Fixtures{}
+	if err := fs.Load(dir); err != nil {
+		t.Fatalf("Load() error %v", err)
+	}
+	if f := fs.Lookup("api/v2/cmdb/system/ha", nil); f == nil || strings.Contains(string(f.Body), "true") {
+		t.Errorf("Lookup() returned %v, expected ha-config.jsonnet", f)
+	}
+
+	// The fixtures of the tests have variants too, their base fixture is served
+	fs = loadTestdata(t)
+	for path, file := range map[string]string{
+		"api/v2/cmdb/system/ha":                "ha-config.jsonnet",
+		"api/v2/cmdb/firewall/policy":          "fw-policy-config.jsonnet",
+		"api/v2/monitor/license/status/select": "license-status.jsonnet",
+	} {
+		q, _ := url.ParseQuery("vdom=*")
+		if f := fs.Lookup(path, q); f == nil || filepath.Base(f.File) != file {
+			t.Errorf("Lookup(%q) returned %v, expected %s", path, f, file)
+		}
+	}
+
+	// Without a shorter base fixture it is not clear which one to serve
+	if err := os.WriteFile(filepath.Join(dir, "ha-config-read-only.jsonnet"), []byte("# api/v2/cmdb/system/ha\n{}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "ha-config.jsonnet")); err != nil {
+		t.Fatal(err)
+	}
+	err := Fixtures{}.Load(dir)
+	if err == nil || !strings.Contains(err.Error(), "same path and query") {
+		t.Errorf("Load() error %v, expected error for fixtures of the same length", err)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	h := &Handler{Fixtures: loadTestdata(t), Options: Options{Token: "secret"}}
+	for _, tc := range []struct {
+		target string
+		auth   string
+		code   int
+	}{
+		{"/api/v2/monitor/system/status", "Bearer secret", http.StatusOK},
+		{"/api/v2/monitor/system/status?access_token=secret", "", http.StatusOK},
+		{"/api/v2/monitor/system/status", "Bearer wrong", http.StatusUnauthorized},
+		{"/api/v2/monitor/nothing", "Bearer secret", http.StatusNotFound},
+	} {
+		r := httptest.NewRequest("GET", tc.target, nil)
+		if tc.auth != "" {
+			r.Header.Set("Authorization", tc.auth)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != tc.code {
+			t.Errorf("GET %s (%q) = %d, expected %d", tc.target, tc.auth, w.Code, tc.code)
+		}
+	}
+
+	h.Options = Options{ErrorRate: 1, ErrorCode: http.StatusServiceUnavailable}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/v2/monitor/system/status", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("error injection returned %d, expected %d", w.Code, http.StatusServiceUnavailable)
+	}
+}
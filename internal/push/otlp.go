@@ -0,0 +1,132 @@
+// Copyright 2025 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package push
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+
+	"github.com/prometheus-community/fortigate_exporter/pkg/probe"
+	dto "github.com/prometheus/client_model/go"
+	colmetricpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricpb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// ucumUnits maps the units of metric names to the UCUM units used by OpenTelemetry
+var ucumUnits = map[string]string{
+	"seconds": "s",
+	"bytes":   "By",
+	"ratio":   "1",
+	"celsius": "Cel",
+	"volts":   "V",
+	"watt":    "W",
+	"rpm":     "{rotation}/min",
+	"bps":     "bit/s",
+	"amperes": "A",
+	"dbm":     "dBm",
+}
+
+// otlp encodes batches as OTLP/HTTP protobuf export requests
+type otlp struct {
+	version string
+}
+
+func (o otlp) encode(b *Batch) ([]byte, http.Header, error) {
+	ts := uint64(b.Time.UnixNano())
+
+	var metrics []*metricpb.Metric
+	for _, mf := range b.Families {
+		m := &metricpb.Metric{
+			Name:        mf.GetName(),
+			Description: mf.GetHelp(),
+			Unit:        ucumUnits[probe.MetricUnit(mf)],
+		}
+		var points []*metricpb.NumberDataPoint
+		for _, dm := range mf.GetMetric() {
+			p := &metricpb.NumberDataPoint{TimeUnixNano: ts}
+			for _, l := range dm.GetLabel() {
+				p.Attributes = append(p.Attributes, stringKeyValue(l.GetName(), l.GetValue()))
+			}
+			var v float64
+			switch mf.GetType() {
+			case dto.MetricType_COUNTER:
+				v = dm.GetCounter().GetValue()
+				if ct := dm.GetCounter().GetCreatedTimestamp(); ct != nil {
+					p.StartTimeUnixNano = uint64(ct.AsTime().UnixNano())
+				}
+			case dto.MetricType_GAUGE:
+				v = dm.GetGauge().GetValue()
+			case dto.MetricType_UNTYPED:
+				v = dm.GetUntyped().GetValue()
+			default:
+				// Probes only produce counters and gauges
+				continue
+			}
+			p.Value = &metricpb.NumberDataPoint_AsDouble{AsDouble: v}
+			points = append(points, p)
+		}
+		if len(points) == 0 {
+			continue
+		}
+		if mf.GetType() == dto.MetricType_COUNTER {
+			m.Data = &metricpb.Metric_Sum{Sum: &metricpb.Sum{
+				DataPoints:             points,
+				AggregationTemporality: metricpb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+				IsMonotonic:            true,
+			}}
+		} else {
+			m.Data = &metricpb.Metric_Gauge{Gauge: &metricpb.Gauge{DataPoints: points}}
+		}
+		metrics = append(metrics, m)
+	}
+
+	res := &resourcepb.Resource{}
+	for _, a := range b.resource() {
+		res.Attributes = append(res.Attributes, stringKeyValue(a.Name, a.Value))
+	}
+	req := &colmetricpb.ExportMetricsServiceRequest{
+		ResourceMetrics: []*metricpb.ResourceMetrics{{
+			Resource: res,
+			ScopeMetrics: []*metricpb.ScopeMetrics{{
+				Scope:   &commonpb.InstrumentationScope{Name: "github.com/prometheus-community/fortigate_exporter", Version: o.version},
+				Metrics: metrics,
+			}},
+		}},
+	}
+	raw, err := proto.Marshal(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	var body bytes.Buffer
+	zw := gzip.NewWriter(&body)
+	if _, err := zw.Write(raw); err != nil {
+		return nil, nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	h := http.Header{}
+	h.Set("Content-Type", "application/x-protobuf")
+	h.Set("Content-Encoding", "gzip")
+	return body.Bytes(), h, nil
+}
+
+func stringKeyValue(k, v string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: k, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v}}}
+}
@@ -0,0 +1,353 @@
+// Copyright 2025 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package push periodically probes all targets of the auth file and pushes
+// the metrics to an OTLP or Prometheus remote write endpoint, for sites that
+// cannot be scraped.
+package push
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus-community/fortigate_exporter/internal/config"
+	"github.com/prometheus-community/fortigate_exporter/pkg/probe"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// jobName is used as job label and service.name of pushed metrics
+const jobName = "fortigate_exporter"
+
+var (
+	pushSent = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "fortigate_exporter_push_sent_batches_total",
+		Help: "Number of probe results pushed successfully",
+	})
+	pushFailed = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "fortigate_exporter_push_failed_requests_total",
+		Help: "Number of push requests that failed and will be retried",
+	})
+	pushDropped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "fortigate_exporter_push_dropped_batches_total",
+		Help: "Number of probe results dropped without being pushed, by reason",
+	}, []string{"reason"})
+	pushQueueLength = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "fortigate_exporter_push_queue_length",
+		Help: "Number of probe results waiting to be pushed",
+	})
+)
+
+// Batch holds the metrics of one probe of a target
+type Batch struct {
+	Target   string
+	Time     time.Time
+	Families []*dto.MetricFamily
+}
+
+// label is a name/value pair, used for both labels and resource attributes
+type label struct {
+	Name  string
+	Value string
+}
+
+// resource returns the attributes describing the target of b, following the
+// OpenTelemetry conventions for Prometheus compatibility
+func (b *Batch) resource() []label {
+	instance := b.Target
+	if u, err := url.Parse(b.Target); err == nil && u.Host != "" {
+		instance = u.Hostname()
+	}
+	attrs := []label{
+		{"service.name", jobName},
+		{"service.instance.id", instance},
+		{"url.full", b.Target},
+	}
+	for _, mf := range b.Families {
+		if mf.GetName() != "fortigate_version_info" || len(mf.GetMetric()) == 0 {
+			continue
+		}
+		for _, l := range mf.GetMetric()[0].GetLabel() {
+			attrs = append(attrs, label{"fortigate." + l.GetName(), l.GetValue()})
+		}
+	}
+	sort.Slice(attrs, func(i, j int) bool { return attrs[i].Name < attrs[j].Name })
+	return attrs
+}
+
+// encoder turns a batch into the body and headers of a push request
+type encoder interface {
+	encode(b *Batch) ([]byte, http.Header, error)
+}
+
+// permanentError marks a push that must not be retried
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// queue holds batches waiting to be pushed, dropping the oldest when full
+type queue struct {
+	mu      sync.Mutex
+	batches []*Batch
+	size    int
+	ready   chan struct{}
+}
+
+func newQueue(size int) *queue {
+	return &queue{size: size, ready: make(chan struct{}, 1)}
+}
+
+func (q *queue) push(b *Batch) {
+	q.mu.Lock()
+	if len(q.batches) >= q.size {
+		q.batches = q.batches[1:]
+		pushDropped.WithLabelValues("queue_full").Inc()
+		slog.Warn("Push queue full, dropping oldest probe result")
+	}
+	q.batches = append(q.batches, b)
+	pushQueueLength.Set(float64(len(q.batches)))
+	q.mu.Unlock()
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// head waits for the oldest batch, it is only removed by remove
+func (q *queue) head(ctx context.Context) (*Batch, bool) {
+	for {
+		q.mu.Lock()
+		if len(q.batches) > 0 {
+			b := q.batches[0]
+			q.mu.Unlock()
+			return b, true
+		}
+		q.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return nil, false
+		case <-q.ready:
+		}
+	}
+}
+
+// remove removes b if it has not been dropped in the meantime
+func (q *queue) remove(b *Batch) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.batches) > 0 && q.batches[0] == b {
+		q.batches = q.batches[1:]
+	}
+	pushQueueLength.Set(float64(len(q.batches)))
+}
+
+// Pusher probes targets on an interval and pushes the results
+type Pusher struct {
+	url      string
+	client   *http.Client
+	encoder  encoder
+	queue    *queue
+	interval time.Duration
+	timeout  time.Duration
+	config   config.FortiExporterConfig
+
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+// New returns a Pusher for the push settings of savedConfig
+func New(savedConfig config.FortiExporterConfig, version string) (*Pusher, error) {
+	p := &Pusher{
+		url:        savedConfig.PushURL,
+		client:     &http.Client{Timeout: 30 * time.Second},
+		queue:      newQueue(savedConfig.PushQueueSize),
+		interval:   time.Duration(savedConfig.PushInterval) * time.Second,
+		timeout:    time.Duration(savedConfig.ScrapeTimeout) * time.Second,
+		config:     savedConfig,
+		minBackoff: time.Second,
+		maxBackoff: time.Minute,
+	}
+	switch savedConfig.PushProtocol {
+	case "remote-write":
+		p.encoder = remoteWrite{}
+	case "otlp":
+		p.encoder = otlp{version: version}
+	default:
+		return nil, fmt.Errorf("unknown push protocol %q", savedConfig.PushProtocol)
+	}
+	return p, nil
+}
+
+// Targets returns the targets of the auth file that can be probed without
+// further parameters, i.e. all URLs with a token
+func Targets(keys config.AuthKeys) []string {
+	var targets []string
+	for t, a := range keys {
+		u, err := url.Parse(string(t))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || a.Token == "" {
+			continue
+		}
+		targets = append(targets, string(t))
+	}
+	sort.Strings(targets)
+	return targets
+}
+
+// Run probes all targets and pushes their metrics until ctx is cancelled
+func (p *Pusher) Run(ctx context.Context) {
+	targets := Targets(p.config.AuthKeys)
+	slog.Info("Pushing metrics", "url", redact(p.url), "protocol", p.config.PushProtocol, "targets", len(targets), "interval", p.interval)
+	var wg sync.WaitGroup
+	for _, t := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.poll(ctx, t)
+		}()
+	}
+	p.send(ctx)
+	wg.Wait()
+}
+
+func (p *Pusher) poll(ctx context.Context, target string) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		if b, err := p.collect(ctx, target); err != nil {
+			slog.Warn("Probe for push failed", "target", target, "err", err)
+		} else {
+			p.queue.push(b)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// collect probes target the way ProbeHandler does
+func (p *Pusher) collect(ctx context.Context, target string) (*Batch, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	probeSuccessGauge := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "probe_success",
+		Help: "Whether or not the probe succeeded",
+	})
+	probeDurationGauge := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "probe_duration_seconds",
+		Help: "How many seconds the probe took to complete",
+	})
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(probeSuccessGauge, probeDurationGauge)
+	pc := &probe.ProbeCollector{}
+	registry.MustRegister(pc)
+	start := time.Now()
+	success, err := pc.Probe(ctx, map[string]string{"target": target}, p.config)
+	if err != nil {
+		return nil, err
+	}
+	probeDurationGauge.Set(time.Since(start).Seconds())
+	if success {
+		probeSuccessGauge.Set(1)
+	}
+	mfs, err := registry.Gather()
+	if err != nil {
+		return nil, err
+	}
+	return &Batch{Target: target, Time: start, Families: mfs}, nil
+}
+
+// send pushes queued batches, retrying with backoff until they are accepted,
+// rejected or dropped from the queue
+func (p *Pusher) send(ctx context.Context) {
+	backoff := p.minBackoff
+	for {
+		b, ok := p.queue.head(ctx)
+		if !ok {
+			return
+		}
+		err := p.push(ctx, b)
+		var perr permanentError
+		switch {
+		case err == nil:
+			p.queue.remove(b)
+			pushSent.Inc()
+			backoff = p.minBackoff
+			continue
+		case errors.As(err, &perr):
+			p.queue.remove(b)
+			pushDropped.WithLabelValues("rejected").Inc()
+			slog.Error("Push rejected, dropping probe result", "target", b.Target, "err", err)
+			continue
+		}
+		pushFailed.Inc()
+		slog.Warn("Push failed, retrying", "target", b.Target, "err", err, "backoff", backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, p.maxBackoff)
+	}
+}
+
+func (p *Pusher) push(ctx context.Context, b *Batch) error {
+	body, header, err := p.encoder.encode(b)
+	if err != nil {
+		return permanentError{fmt.Errorf("failed to encode metrics: %w", err)}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return permanentError{err}
+	}
+	req.Header = header
+	req.Header.Set("User-Agent", jobName)
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 == 2 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("server returned %s: %s", resp.Status, bytes.TrimSpace(msg))
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode/100 == 5 {
+		return err
+	}
+	return permanentError{err}
+}
+
+// redact hides credentials in the push URL for logging
+func redact(s string) string {
+	u, err := url.Parse(s)
+	if err != nil {
+		return s
+	}
+	return u.Redacted()
+}
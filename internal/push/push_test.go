@@ -0,0 +1,443 @@
+// Copyright 2025 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package push
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/prometheus-community/fortigate_exporter/internal/config"
+	"github.com/prometheus-community/fortigate_exporter/internal/exportertest"
+	"github.com/prometheus-community/fortigate_exporter/pkg/probe"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	colmetricpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// receiver is a push endpoint recording what it receives. It answers with
+// the queued status codes first, then with 200.
+type receiver struct {
+	t        *testing.T
+	mu       sync.Mutex
+	codes    []int
+	requests int
+	series   [][]string
+	otlp     []*colmetricpb.ExportMetricsServiceRequest
+	received chan struct{}
+}
+
+func newReceiver(t *testing.T, codes ...int) (*receiver, *httptest.Server) {
+	r := &receiver{t: t, codes: codes, received: make(chan struct{}, 100)}
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return r, srv
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests++
+	if len(r.codes) > 0 {
+		code := r.codes[0]
+		r.codes = r.codes[1:]
+		http.Error(w, "injected failure", code)
+		return
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		r.t.Errorf("failed to read push request: %v", err)
+		return
+	}
+	switch req.Header.Get("Content-Encoding") {
+	case "snappy":
+		raw, err := snappy.Decode(nil, body)
+		if err != nil {
+			r.t.Errorf("failed to decode remote write request: %v", err)
+			return
+		}
+		r.series = append(r.series, decodeWriteRequest(r.t, raw)...)
+	case "gzip":
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			r.t.Errorf("failed to decompress OTLP request: %v", err)
+			return
+		}
+		raw, _ := io.ReadAll(zr)
+		er := &colmetricpb.ExportMetricsServiceRequest{}
+		if err := proto.Unmarshal(raw, er); err != nil {
+			r.t.Errorf("failed to decode OTLP request: %v", err)
+			return
+		}
+		r.otlp = append(r.otlp, er)
+	default:
+		r.t.Errorf("unexpected Content-Encoding %q", req.Header.Get("Content-Encoding"))
+	}
+	r.received <- struct{}{}
+}
+
+func (r *receiver) wait(t *testing.T) {
+	select {
+	case <-r.received:
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for push")
+	}
+}
+
+// decodeWriteRequest returns the labels of every series of a WriteRequest as
+// name="value" strings, followed by the sample value
+func decodeWriteRequest(t *testing.T, b []byte) [][]string {
+	var out [][]string
+	fields := func(b []byte, f func(num protowire.Number, typ protowire.Type, v []byte, u uint64)) {
+		for len(b) > 0 {
+			num, typ, n := protowire.ConsumeTag(b)
+			if n < 0 {
+				t.Fatalf("invalid tag: %v", protowire.ParseError(n))
+			}
+			b = b[n:]
+			switch typ {
+			case protowire.BytesType:
+				v, n := protowire.ConsumeBytes(b)
+				f(num, typ, v, 0)
+				b = b[n:]
+			case protowire.VarintType:
+				v, n := protowire.ConsumeVarint(b)
+				f(num, typ, nil, v)
+				b = b[n:]
+			case protowire.Fixed64Type:
+				v, n := protowire.ConsumeFixed64(b)
+				f(num, typ, nil, v)
+				b = b[n:]
+			default:
+				t.Fatalf("unexpected wire type %v", typ)
+			}
+		}
+	}
+	fields(b, func(_ protowire.Number, _ protowire.Type, ts []byte, _ uint64) {
+		var labels []string
+		var value float64
+		fields(ts, func(num protowire.Number, _ protowire.Type, v []byte, _ uint64) {
+			switch num {
+			case 1:
+				var name, val string
+				fields(v, func(num protowire.Number, _ protowire.Type, v []byte, _ uint64) {
+					if num == 1 {
+						name = string(v)
+					} else {
+						val = string(v)
+					}
+				})
+				labels = append(labels, fmt.Sprintf("%s=%q", name, val))
+			case 2:
+				fields(v, func(num protowire.Number, _ protowire.Type, _ []byte, u uint64) {
+					if num == 1 {
+						value = math.Float64frombits(u)
+					}
+				})
+			}
+		})
+		out = append(out, append(labels, fmt.Sprint(value)))
+	})
+	return out
+}
+
+// testBatch returns a batch as produced by probing a target
+func testBatch(t *testing.T) *Batch {
+	versionInfo := prometheus.NewDesc("fortigate_version_info", "System version and build information", []string{"serial", "version", "build"}, nil)
+	hits := prometheus.NewDesc("fortigate_policy_hit_count_total", "Number of times a policy has been hit", []string{"vdom", "id"}, nil)
+	rtt := prometheus.NewDesc("fortigate_lb_real_server_rtt_seconds", "RTT", []string{"id"}, nil)
+	r := prometheus.NewRegistry()
+	r.MustRegister(constCollector{
+		prometheus.MustNewConstMetric(versionInfo, prometheus.GaugeValue, 1, "FGT0000000000001", "v7.4.4", "2662"),
+		prometheus.MustNewConstMetricWithCreatedTimestamp(hits, prometheus.CounterValue, 42, time.Unix(1589924891, 0), "root", "1"),
+		prometheus.MustNewConstMetric(rtt, prometheus.GaugeValue, 0.002, "1"),
+	})
+	mfs, err := r.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Batch{Target: "https://fw1.example.com:8443", Time: time.Unix(1700000000, 0), Families: mfs}
+}
+
+type constCollector []prometheus.Metric
+
+func (c constCollector) Describe(ch chan<- *prometheus.Desc) { prometheus.DescribeByCollect(c, ch) }
+func (c constCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, m := range c {
+		ch <- m
+	}
+}
+
+func newTestPusher(t *testing.T, url, protocol string) *Pusher {
+	p, err := New(config.FortiExporterConfig{PushURL: url, PushProtocol: protocol, PushQueueSize: 2, PushInterval: 1, ScrapeTimeout: 5}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.minBackoff = time.Millisecond
+	p.maxBackoff = 10 * time.Millisecond
+	return p
+}
+
+// sendAll runs the sender until the queue is empty
+func sendAll(t *testing.T, p *Pusher) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		p.send(ctx)
+		close(done)
+	}()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		p.queue.mu.Lock()
+		n := len(p.queue.batches)
+		p.queue.mu.Unlock()
+		if n == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the queue to drain")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+}
+
+func TestRemoteWrite(t *testing.T) {
+	r, srv := newReceiver(t)
+	p := newTestPusher(t, srv.URL, "remote-write")
+	p.queue.push(testBatch(t))
+	sendAll(t, p)
+
+	var got []string
+	for _, s := range r.series {
+		got = append(got, strings.Join(s, " "))
+	}
+	sort.Strings(got)
+	want := []string{
+		`__name__="fortigate_lb_real_server_rtt_seconds" id="1" instance="fw1.example.com" job="fortigate_exporter" 0.002`,
+		`__name__="fortigate_policy_hit_count_total" id="1" instance="fw1.example.com" job="fortigate_exporter" vdom="root" 42`,
+		`__name__="fortigate_version_info" build="2662" instance="fw1.example.com" job="fortigate_exporter" serial="FGT0000000000001" version="v7.4.4" 1`,
+		`__name__="target_info" fortigate_build="2662" fortigate_serial="FGT0000000000001" fortigate_version="v7.4.4" instance="fw1.example.com" job="fortigate_exporter" url_full="https://fw1.example.com:8443" 1`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected series:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestOTLP(t *testing.T) {
+	r, srv := newReceiver(t)
+	p := newTestPusher(t, srv.URL, "otlp")
+	p.queue.push(testBatch(t))
+	sendAll(t, p)
+
+	if len(r.otlp) != 1 || len(r.otlp[0].ResourceMetrics) != 1 {
+		t.Fatalf("expected one export request with one resource, got %v", r.otlp)
+	}
+	rm := r.otlp[0].ResourceMetrics[0]
+	attrs := map[string]string{}
+	for _, kv := range rm.GetResource().GetAttributes() {
+		attrs[kv.GetKey()] = kv.GetValue().GetStringValue()
+	}
+	for k, v := range map[string]string{
+		"service.name":        "fortigate_exporter",
+		"service.instance.id": "fw1.example.com",
+		"fortigate.serial":    "FGT0000000000001",
+		"fortigate.version":   "v7.4.4",
+	} {
+		if attrs[k] != v {
+			t.Errorf("resource attribute %s = %q, want %q", k, attrs[k], v)
+		}
+	}
+
+	metrics := map[string]string{}
+	for _, m := range rm.GetScopeMetrics()[0].GetMetrics() {
+		switch {
+		case m.GetSum() != nil:
+			dp := m.GetSum().GetDataPoints()[0]
+			metrics[m.GetName()] = fmt.Sprintf("sum %s %v start=%d", m.GetUnit(), dp.GetAsDouble(), dp.GetStartTimeUnixNano()/1e9)
+		case m.GetGauge() != nil:
+			metrics[m.GetName()] = fmt.Sprintf("gauge %s %v", m.GetUnit(), m.GetGauge().GetDataPoints()[0].GetAsDouble())
+		}
+	}
+	for name, want := range map[string]string{
+		"fortigate_policy_hit_count_total":     "sum  42 start=1589924891",
+		"fortigate_lb_real_server_rtt_seconds": "gauge s 0.002",
+		"fortigate_version_info":               "gauge  1",
+	} {
+		if metrics[name] != want {
+			t.Errorf("metric %s = %q, want %q", name, metrics[name], want)
+		}
+	}
+}
+
+func TestPushRetries(t *testing.T) {
+	r, srv := newReceiver(t, http.StatusServiceUnavailable, http.StatusTooManyRequests)
+	p := newTestPusher(t, srv.URL, "remote-write")
+	failed := testutil.ToFloat64(pushFailed)
+	p.queue.push(testBatch(t))
+	sendAll(t, p)
+
+	if r.requests != 3 || len(r.series) == 0 {
+		t.Errorf("expected the batch to be delivered on the third request, got %d requests", r.requests)
+	}
+	if d := testutil.ToFloat64(pushFailed) - failed; d != 2 {
+		t.Errorf("expected 2 failed requests, got %v", d)
+	}
+}
+
+func TestPushRejected(t *testing.T) {
+	r, srv := newReceiver(t, http.StatusBadRequest)
+	p := newTestPusher(t, srv.URL, "remote-write")
+	rejected := testutil.ToFloat64(pushDropped.WithLabelValues("rejected"))
+	p.queue.push(testBatch(t))
+	sendAll(t, p)
+
+	if r.requests != 1 {
+		t.Errorf("expected a rejected batch not to be retried, got %d requests", r.requests)
+	}
+	if d := testutil.ToFloat64(pushDropped.WithLabelValues("rejected")) - rejected; d != 1 {
+		t.Errorf("expected 1 rejected batch, got %v", d)
+	}
+}
+
+func TestQueueDropsOldest(t *testing.T) {
+	q := newQueue(2)
+	bs := []*Batch{{Target: "a"}, {Target: "b"}, {Target: "c"}}
+	for _, b := range bs {
+		q.push(b)
+	}
+	head, _ := q.head(context.Background())
+	if head != bs[1] {
+		t.Fatalf("expected the oldest batch to be dropped, head is %q", head.Target)
+	}
+	// Removing a batch that was dropped while being sent must not remove another one
+	q.remove(bs[0])
+	if len(q.batches) != 2 {
+		t.Errorf("remove() of a dropped batch changed the queue")
+	}
+}
+
+func TestTargets(t *testing.T) {
+	got := Targets(config.AuthKeys{
+		"https://fw2":   {Token: "a"},
+		"https://fw1":   {Token: "b"},
+		"some-profile":  {},
+		"https://fw3":   {},
+		"http://fw4:80": {Token: "c"},
+	})
+	if want := []string{"http://fw4:80", "https://fw1", "https://fw2"}; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Targets() = %v, want %v", got, want)
+	}
+}
+
+// configureMock starts a mock FortiGate and configures the exporter to probe
+// it and push its metrics to pushURL
+func configureMock(t *testing.T, pushURL string) *httptest.Server {
+	fw := exportertest.Serve(t, exportertest.Fixtures(t, "7.4"), "push-token")
+	auth := fmt.Sprintf("%q:\n  token: push-token\n  probes:\n    include:\n      - System/Status\n", fw.URL)
+	exportertest.Configure(t, auth, map[string]string{"push-url": pushURL, "push-protocol": "remote-write"})
+	return fw
+}
+
+// TestRunAgainstMock probes a mock FortiGate from the polling loop and checks
+// the pushed result
+func TestRunAgainstMock(t *testing.T) {
+	r, srv := newReceiver(t)
+	configureMock(t, srv.URL)
+
+	p, err := New(config.GetConfig(), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		p.Run(ctx)
+		close(done)
+	}()
+	r.wait(t)
+	cancel()
+	<-done
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	found := map[string]bool{}
+	for _, s := range r.series {
+		found[s[0]] = true
+		if s[0] == `__name__="probe_success"` && s[len(s)-1] != "1" {
+			t.Errorf("probe_success = %s, want 1", s[len(s)-1])
+		}
+	}
+	for _, name := range []string{"target_info", "fortigate_version_info", "probe_success", "fortigate_exporter_probe_success"} {
+		if !found[fmt.Sprintf("__name__=%q", name)] {
+			t.Errorf("series %s was not pushed, got %v", name, r.series)
+		}
+	}
+}
+
+// TestRunWithDynamicTargets probes targets given with token= on /probe while
+// the polling loop runs, for go test -race
+func TestRunWithDynamicTargets(t *testing.T) {
+	r, srv := newReceiver(t)
+	fw := configureMock(t, srv.URL)
+
+	p, err := New(config.GetConfig(), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		p.Run(ctx)
+		close(done)
+	}()
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Not in the auth file, so the token is added to the auth keys of the request
+			target := fmt.Sprintf("%s/?n=%d", fw.URL, i)
+			req := httptest.NewRequest("GET", "/probe?target="+url.QueryEscape(target)+"&token=push-token&profile="+url.QueryEscape(fw.URL), nil)
+			w := httptest.NewRecorder()
+			probe.ProbeHandler(w, req)
+			if w.Code != http.StatusOK {
+				t.Errorf("ProbeHandler() returned %d: %s", w.Code, w.Body.String())
+			}
+		}()
+	}
+	wg.Wait()
+	r.wait(t)
+	cancel()
+	<-done
+
+	if got := Targets(config.GetConfig().AuthKeys); len(got) != 1 || got[0] != fw.URL {
+		t.Errorf("Targets() = %v after dynamic probes, want only %s", got, fw.URL)
+	}
+}
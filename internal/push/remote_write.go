@@ -0,0 +1,126 @@
+// Copyright 2025 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package push
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/klauspost/compress/snappy"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// remoteWrite encodes batches as Prometheus remote write 1.0 requests. The
+// messages are small enough to be written by hand instead of pulling in the
+// generated code of the Prometheus server.
+type remoteWrite struct{}
+
+func (remoteWrite) encode(b *Batch) ([]byte, http.Header, error) {
+	ts := b.Time.UnixMilli()
+	res := b.resource()
+
+	// job and instance identify the series, the remaining resource attributes
+	// are exposed on target_info like OpenTelemetry does
+	var job, instance string
+	info := []label{{"__name__", "target_info"}}
+	for _, a := range res {
+		switch a.Name {
+		case "service.name":
+			job = a.Value
+		case "service.instance.id":
+			instance = a.Value
+		default:
+			info = append(info, label{sanitizeLabelName(a.Name), a.Value})
+		}
+	}
+	target := []label{{"instance", instance}, {"job", job}}
+
+	var req []byte
+	req = appendSeries(req, append(info, target...), 1, ts)
+	for _, mf := range b.Families {
+		for _, m := range mf.GetMetric() {
+			var v float64
+			switch mf.GetType() {
+			case dto.MetricType_COUNTER:
+				v = m.GetCounter().GetValue()
+			case dto.MetricType_GAUGE:
+				v = m.GetGauge().GetValue()
+			case dto.MetricType_UNTYPED:
+				v = m.GetUntyped().GetValue()
+			default:
+				// Probes only produce counters and gauges
+				continue
+			}
+			ls := []label{{"__name__", mf.GetName()}}
+			for _, l := range m.GetLabel() {
+				name := l.GetName()
+				// Same as a scrape without honor_labels
+				if name == "job" || name == "instance" {
+					name = "exported_" + name
+				}
+				ls = append(ls, label{name, l.GetValue()})
+			}
+			ls = append(ls, target...)
+			req = appendSeries(req, ls, v, ts)
+		}
+	}
+
+	h := http.Header{}
+	h.Set("Content-Type", "application/x-protobuf")
+	h.Set("Content-Encoding", "snappy")
+	h.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	return snappy.Encode(nil, req), h, nil
+}
+
+// appendSeries appends a TimeSeries with a single sample to a WriteRequest
+func appendSeries(req []byte, labels []label, value float64, ts int64) []byte {
+	sort.Slice(labels, func(i, j int) bool { return labels[i].Name < labels[j].Name })
+	var series []byte
+	for _, l := range labels {
+		if l.Value == "" {
+			continue
+		}
+		var lb []byte
+		lb = protowire.AppendTag(lb, 1, protowire.BytesType)
+		lb = protowire.AppendString(lb, l.Name)
+		lb = protowire.AppendTag(lb, 2, protowire.BytesType)
+		lb = protowire.AppendString(lb, l.Value)
+		series = protowire.AppendTag(series, 1, protowire.BytesType)
+		series = protowire.AppendBytes(series, lb)
+	}
+	var sample []byte
+	sample = protowire.AppendTag(sample, 1, protowire.Fixed64Type)
+	sample = protowire.AppendFixed64(sample, math.Float64bits(value))
+	sample = protowire.AppendTag(sample, 2, protowire.VarintType)
+	sample = protowire.AppendVarint(sample, uint64(ts))
+	series = protowire.AppendTag(series, 2, protowire.BytesType)
+	series = protowire.AppendBytes(series, sample)
+
+	req = protowire.AppendTag(req, 1, protowire.BytesType)
+	return protowire.AppendBytes(req, series)
+}
+
+// sanitizeLabelName replaces the characters of a resource attribute that are
+// not valid in a label name
+func sanitizeLabelName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
@@ -0,0 +1,149 @@
+// Copyright 2025 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus-community/fortigate_exporter/internal/config"
+)
+
+// targetClient is a long-lived HTTP client bound to a single target.
+// It keeps its own connection pool and caps the number of requests in flight.
+// Requests are instrumented once they got past the cap, so the time spent
+// waiting for a free slot does not count as API latency.
+type targetClient struct {
+	hc   *http.Client
+	next HTTPClient
+	sem  chan struct{}
+	// used is when the client was last handed out by the pool
+	used time.Time
+}
+
+func (c *targetClient) Do(req *http.Request) (*http.Response, error) {
+	if c.sem == nil {
+		return c.next.Do(req)
+	}
+	select {
+	case c.sem <- struct{}{}:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+	resp, err := c.next.Do(req)
+	if err != nil {
+		<-c.sem
+		return nil, err
+	}
+	// The request counts as in flight until the caller is done with the body
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: func() { <-c.sem }}
+	return resp, nil
+}
+
+type releasingBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+// clientRetention is how long a client is kept after it was last used, so
+// targets that are no longer probed, e.g. ones given with token=, do not keep
+// their transport and idle connections forever
+const clientRetention = time.Hour
+
+type clientPool struct {
+	mu          sync.Mutex
+	transport   *http.Transport
+	maxRequests int
+	clients     map[string]*targetClient
+}
+
+var pool = &clientPool{
+	transport: http.DefaultTransport.(*http.Transport),
+	clients:   map[string]*targetClient{},
+}
+
+// reset replaces the transport template and drops all existing clients
+func (p *clientPool) reset(t *http.Transport, maxRequests int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, c := range p.clients {
+		c.hc.CloseIdleConnections()
+	}
+	p.transport = t
+	p.maxRequests = maxRequests
+	p.clients = map[string]*targetClient{}
+}
+
+// get returns the client for tgt with the settings of auth. Clients are keyed
+// by target and settings, so changed settings take effect after a reload.
+// Clients not used within clientRetention are dropped.
+func (p *clientPool) get(tgt url.URL, auth config.TargetAuth, now time.Time) (*targetClient, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for k, c := range p.clients {
+		if now.Sub(c.used) > clientRetention {
+			c.hc.CloseIdleConnections()
+			delete(p.clients, k)
+		}
+	}
+	limit := p.maxRequests
+	if auth.MaxRequests > 0 {
+		limit = auth.MaxRequests
+	}
+	key := tgt.String() + "\x00" + auth.ProxyURL + "\x00" + strconv.Itoa(limit)
+	if c, ok := p.clients[key]; ok {
+		c.used = now
+		return c, nil
+	}
+	t := p.transport.Clone()
+	if auth.ProxyURL != "" {
+		// Overrides the HTTP(S)_PROXY environment variables for this target
+		pu, err := config.ParseProxyURL(auth.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy_url for %q: %v", tgt.String(), err)
+		}
+		t.Proxy = http.ProxyURL(pu)
+	}
+	hc := &http.Client{Transport: t}
+	c := &targetClient{hc: hc, next: &instrumentedClient{target: tgt.String(), next: hc}, used: now}
+	if limit > 0 {
+		c.sem = make(chan struct{}, limit)
+		t.MaxIdleConnsPerHost = limit
+	}
+	p.clients[key] = c
+	return c, nil
+}
+
+// TargetClient returns the shared HTTP client for tgt, creating it on first use.
+// Clients are kept while the target is probed so connections to the target are
+// reused between scrapes.
+func TargetClient(tgt url.URL, aConfig config.FortiExporterConfig) (HTTPClient, error) {
+	c, err := pool.get(tgt, aConfig.AuthKeys[config.Target(tgt.String())], time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
@@ -0,0 +1,273 @@
+// Copyright 2025 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus-community/fortigate_exporter/internal/config"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestTargetClientReused(t *testing.T) {
+	p := &clientPool{}
+	p.reset(http.DefaultTransport.(*http.Transport).Clone(), 2)
+	u := url.URL{Scheme: "https", Host: "fw1"}
+	a, _ := p.get(u, config.TargetAuth{}, time.Now())
+	b, _ := p.get(u, config.TargetAuth{}, time.Now())
+	if a != b {
+		t.Errorf("get() returned different clients for the same target")
+	}
+	if cap(a.sem) != 2 {
+		t.Errorf("get() limit %d, expected 2", cap(a.sem))
+	}
+	c, _ := p.get(url.URL{Scheme: "https", Host: "fw2"}, config.TargetAuth{MaxRequests: 5}, time.Now())
+	if cap(c.sem) != 5 {
+		t.Errorf("get() limit %d, expected per-target override 5", cap(c.sem))
+	}
+}
+
+func TestTargetClientSettingsAndEviction(t *testing.T) {
+	p := &clientPool{}
+	p.reset(http.DefaultTransport.(*http.Transport).Clone(), 2)
+	u := url.URL{Scheme: "https", Host: "fw1"}
+	now := time.Unix(1700000000, 0)
+	a, _ := p.get(u, config.TargetAuth{}, now)
+	// Changed settings, e.g. after a reload, get a new client
+	b, _ := p.get(u, config.TargetAuth{MaxRequests: 5}, now)
+	if a == b || cap(b.sem) != 5 {
+		t.Errorf("get() did not apply the changed max_requests")
+	}
+	c, _ := p.get(u, config.TargetAuth{ProxyURL: "http://proxy:3128"}, now)
+	if c == a || c == b {
+		t.Errorf("get() did not apply the changed proxy_url")
+	}
+
+	p.get(url.URL{Scheme: "https", Host: "fw2"}, config.TargetAuth{}, now.Add(clientRetention))
+	if len(p.clients) != 4 {
+		t.Errorf("%d clients within retention, expected 4", len(p.clients))
+	}
+	p.get(url.URL{Scheme: "https", Host: "fw2"}, config.TargetAuth{}, now.Add(clientRetention+time.Second))
+	if len(p.clients) != 1 {
+		t.Errorf("%d clients after retention, expected only the one still in use", len(p.clients))
+	}
+}
+
+func TestTargetClientLimit(t *testing.T) {
+	var inFlight, peak int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	p := &clientPool{}
+	p.reset(http.DefaultTransport.(*http.Transport).Clone(), 2)
+	u, _ := url.Parse(srv.URL)
+	c, _ := p.get(*u, config.TargetAuth{}, time.Now())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, _ := http.NewRequest("GET", srv.URL, nil)
+			resp, err := c.Do(req)
+			if err != nil {
+				t.Errorf("Do() error %v", err)
+				return
+			}
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+	if peak > 2 {
+		t.Errorf("%d requests in flight, expected at most 2", peak)
+	}
+}
+
+func TestTargetClientQueueNotInstrumented(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	p := &clientPool{}
+	p.reset(http.DefaultTransport.(*http.Transport).Clone(), 1)
+	u, _ := url.Parse(srv.URL)
+	c, _ := p.get(*u, config.TargetAuth{}, time.Now())
+
+	// Occupy the only slot so the request has to wait for it
+	c.sem <- struct{}{}
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		<-c.sem
+	}()
+	req, _ := http.NewRequest("GET", srv.URL+"/api/v2/monitor/system/status", nil)
+	resp, err := c.Do(req)
+	if err != nil {
+		t.Fatalf("Do() error %v", err)
+	}
+	io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	m := &dto.Metric{}
+	if err := apiRequestDuration.WithLabelValues(u.String(), "/api/v2/monitor/system/status").(prometheus.Histogram).Write(m); err != nil {
+		t.Fatal(err)
+	}
+	if h := m.GetHistogram(); h.GetSampleCount() != 1 || h.GetSampleSum() >= 0.2 {
+		t.Errorf("observed %d requests taking %vs, expected one without the time spent waiting", h.GetSampleCount(), h.GetSampleSum())
+	}
+}
+
+func TestTargetClientProxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		w.Write([]byte(`{}`))
+	}))
+	defer proxy.Close()
+
+	p := &clientPool{}
+	p.reset(http.DefaultTransport.(*http.Transport).Clone(), 0)
+	u := url.URL{Scheme: "http", Host: "fw-behind-proxy"}
+	c, err := p.get(u, config.TargetAuth{ProxyURL: proxy.URL}, time.Now())
+	if err != nil {
+		t.Fatalf("get() error %v", err)
+	}
+	req, _ := http.NewRequest("GET", "http://fw-behind-proxy/api/v2/monitor/system/status", nil)
+	resp, err := c.Do(req)
+	if err != nil {
+		t.Fatalf("Do() error %v", err)
+	}
+	resp.Body.Close()
+	if proxied != "http://fw-behind-proxy/api/v2/monitor/system/status" {
+		t.Errorf("proxy saw %q, expected request for the target", proxied)
+	}
+
+	if _, err := p.get(url.URL{Scheme: "https", Host: "fw2"}, config.TargetAuth{ProxyURL: "ftp://proxy"}, time.Now()); err == nil {
+		t.Errorf("get() expected error for unsupported proxy scheme")
+	}
+}
+
+// serveSOCKS5 answers a single SOCKS5 CONNECT without authentication on l,
+// sends the requested address to dest and connects it to backend
+func serveSOCKS5(t *testing.T, l net.Listener, backend string, dest chan<- string) {
+	conn, err := l.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	// Greeting: version, number of methods, methods
+	hdr := make([]byte, 2)
+	if _, err := io.ReadFull(conn, hdr); err != nil || hdr[0] != 5 {
+		t.Errorf("invalid SOCKS5 greeting %v: %v", hdr, err)
+		return
+	}
+	if _, err := io.ReadFull(conn, make([]byte, hdr[1])); err != nil {
+		return
+	}
+	conn.Write([]byte{5, 0})
+	// Request: version, command, reserved, address type, address, port
+	req := make([]byte, 4)
+	if _, err := io.ReadFull(conn, req); err != nil || req[1] != 1 {
+		t.Errorf("invalid SOCKS5 request %v: %v", req, err)
+		return
+	}
+	var host string
+	switch req[3] {
+	case 1:
+		ip := make([]byte, 4)
+		io.ReadFull(conn, ip)
+		host = net.IP(ip).String()
+	case 3:
+		n := make([]byte, 1)
+		io.ReadFull(conn, n)
+		name := make([]byte, n[0])
+		io.ReadFull(conn, name)
+		host = string(name)
+	default:
+		t.Errorf("unexpected SOCKS5 address type %d", req[3])
+		return
+	}
+	port := make([]byte, 2)
+	io.ReadFull(conn, port)
+	dest <- net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port))))
+
+	upstream, err := net.Dial("tcp", backend)
+	if err != nil {
+		conn.Write([]byte{5, 5, 0, 1, 0, 0, 0, 0, 0, 0})
+		return
+	}
+	defer upstream.Close()
+	conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+	go io.Copy(upstream, conn)
+	io.Copy(conn, upstream)
+}
+
+func TestTargetClientSOCKS5Proxy(t *testing.T) {
+	var path string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Write([]byte(`{}`))
+	}))
+	defer backend.Close()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	dest := make(chan string, 1)
+	go serveSOCKS5(t, l, backend.Listener.Addr().String(), dest)
+
+	p := &clientPool{}
+	p.reset(http.DefaultTransport.(*http.Transport).Clone(), 0)
+	u := url.URL{Scheme: "http", Host: "fw-behind-socks:8080"}
+	c, err := p.get(u, config.TargetAuth{ProxyURL: "socks5://" + l.Addr().String()}, time.Now())
+	if err != nil {
+		t.Fatalf("get() error %v", err)
+	}
+	req, _ := http.NewRequest("GET", "http://fw-behind-socks:8080/api/v2/monitor/system/status", nil)
+	resp, err := c.Do(req)
+	if err != nil {
+		t.Fatalf("Do() error %v", err)
+	}
+	resp.Body.Close()
+	// The target name is resolved by the proxy, not locally
+	if d := <-dest; d != "fw-behind-socks:8080" {
+		t.Errorf("proxy was asked to connect to %q, expected the target", d)
+	}
+	if path != "/api/v2/monitor/system/status" {
+		t.Errorf("target saw request for %q", path)
+	}
+}
@@ -35,10 +35,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"reflect"
+	"time"
 
 	"github.com/prometheus-community/fortigate_exporter/internal/config"
+	"github.com/prometheus-community/fortigate_exporter/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type HTTPClient interface {
@@ -46,10 +53,11 @@ type HTTPClient interface {
 }
 
 type fortiTokenClient struct {
-	tgt url.URL
-	hc  HTTPClient
-	ctx context.Context
-	tok config.Token
+	tgt    url.URL
+	hc     HTTPClient
+	ctx    context.Context
+	tok    config.Token
+	logger *slog.Logger
 }
 
 func (c *fortiTokenClient) newGetRequest(url string) (*http.Request, error) {
@@ -66,31 +74,73 @@ func (c *fortiTokenClient) Get(path string, query string, obj interface{}) error
 	u.Path = path
 	u.RawQuery = query
 
+	ctx, span := tracing.Tracer().Start(c.ctx, "GET "+path, trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("fortigate.endpoint", path)))
+	defer span.End()
+
 	req, err := c.newGetRequest(u.String())
 	if err != nil {
 		return err
 	}
 
-	req = req.WithContext(c.ctx)
+	start := time.Now()
+	req = req.WithContext(ctx)
 	resp, err := c.hc.Do(req)
 	if err != nil {
-		return err
+		span.SetStatus(codes.Error, err.Error())
+		c.logger.Debug("API request failed", "endpoint", path, "url", redactURL(u), "duration", time.Since(start), "err", err)
+		return fmt.Errorf("request to %q failed: %w", path, err)
 	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	c.logger.Debug("API request", "endpoint", path, "url", redactURL(u), "status", resp.StatusCode, "duration", time.Since(start))
 	if resp.StatusCode != 200 {
+		span.SetStatus(codes.Error, fmt.Sprintf("response code %d", resp.StatusCode))
 		return fmt.Errorf("response code was %d, expected 200 (path: %q)", resp.StatusCode, path)
 	}
-
-	b, err := io.ReadAll(resp.Body)
 	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+	if err := json.Unmarshal(b, obj); err != nil {
+		span.SetStatus(codes.Error, err.Error())
 		return err
 	}
-	return json.Unmarshal(b, obj)
+	// Queries with vdom=* return one result per VDOM
+	if v := reflect.Indirect(reflect.ValueOf(obj)); v.Kind() == reflect.Slice {
+		span.SetAttributes(attribute.Int("fortigate.vdom_count", v.Len()))
+	}
+	return nil
 }
 
 func (c *fortiTokenClient) String() string {
 	return c.tgt.String()
 }
 
+func (c *fortiTokenClient) withLogger(logger *slog.Logger) FortiHTTP {
+	n := *c
+	n.logger = logger
+	return &n
+}
+
+func (c *fortiTokenClient) withContext(ctx context.Context) FortiHTTP {
+	n := *c
+	n.ctx = ctx
+	return &n
+}
+
+// redactURL strips credentials from u so it can be logged
+func redactURL(u url.URL) string {
+	q := u.Query()
+	if q.Has("access_token") {
+		q.Set("access_token", "REDACTED")
+		u.RawQuery = q.Encode()
+	}
+	return u.Redacted()
+}
+
 func newFortiTokenClient(ctx context.Context, tgt url.URL, hc HTTPClient, token config.Token) (*fortiTokenClient, error) {
-	return &fortiTokenClient{tgt, hc, ctx, token}, nil
+	return &fortiTokenClient{tgt, hc, ctx, token, slog.Default()}, nil
 }
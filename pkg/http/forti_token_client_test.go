@@ -38,6 +38,12 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
 type fakeHTTPClient struct {
@@ -83,3 +89,42 @@ func TestGetFail(t *testing.T) {
 		t.Errorf("Get() expected non-nil error, got nil error")
 	}
 }
+
+func TestRedactURL(t *testing.T) {
+	u := url.URL{Scheme: "https", Host: "fw1", Path: "api/v2/monitor/system/status", RawQuery: "vdom=root&access_token=secret"}
+	got := redactURL(u)
+	if strings.Contains(got, "secret") {
+		t.Errorf("redactURL() = %q, expected token to be redacted", got)
+	}
+}
+
+func TestGetSpan(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	otel.SetTracerProvider(tp)
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	c, _ := newClient(200, `[{ "vdom": "root" }, { "vdom": "other" }]`)
+	var v []struct{ VDOM string }
+	if err := c.Get("api/v2/monitor/system/interface", "vdom=*", &v); err != nil {
+		t.Fatalf("Get() error %v", err)
+	}
+
+	spans := sr.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, expected 1", len(spans))
+	}
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, a := range spans[0].Attributes() {
+		attrs[a.Key] = a.Value
+	}
+	if attrs["fortigate.endpoint"].AsString() != "api/v2/monitor/system/interface" {
+		t.Errorf("endpoint attribute %v", attrs["fortigate.endpoint"])
+	}
+	if attrs["fortigate.vdom_count"].AsInt64() != 2 {
+		t.Errorf("vdom_count attribute %v, expected 2", attrs["fortigate.vdom_count"])
+	}
+	if attrs["http.response.status_code"].AsInt64() != 200 {
+		t.Errorf("status attribute %v, expected 200", attrs["http.response.status_code"])
+	}
+}
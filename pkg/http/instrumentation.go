@@ -0,0 +1,85 @@
+// Copyright 2025 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	apiRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "fortigate_exporter_api_requests_total",
+		Help: "Number of FortiOS API requests made, per target, endpoint and response code",
+	}, []string{"target", "endpoint", "code"})
+	apiRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "fortigate_exporter_api_request_duration_seconds",
+		Help:    "Time taken by FortiOS API requests including reading the response, per target and endpoint",
+		Buckets: []float64{.01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+	}, []string{"target", "endpoint"})
+	apiResponseSize = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "fortigate_exporter_api_response_size_bytes",
+		Help:    "Size of FortiOS API response bodies, per target and endpoint",
+		Buckets: prometheus.ExponentialBuckets(256, 4, 9),
+	}, []string{"target", "endpoint"})
+)
+
+// instrumentedClient records request count, latency and response size of
+// every API call towards target on the exporter's own registry.
+type instrumentedClient struct {
+	target string
+	next   HTTPClient
+}
+
+func (c *instrumentedClient) Do(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	endpoint := req.URL.Path
+	resp, err := c.next.Do(req)
+	if err != nil {
+		apiRequests.WithLabelValues(c.target, endpoint, "error").Inc()
+		apiRequestDuration.WithLabelValues(c.target, endpoint).Observe(time.Since(start).Seconds())
+		return nil, err
+	}
+	apiRequests.WithLabelValues(c.target, endpoint, strconv.Itoa(resp.StatusCode)).Inc()
+	resp.Body = &countingBody{ReadCloser: resp.Body, done: func(n int) {
+		apiRequestDuration.WithLabelValues(c.target, endpoint).Observe(time.Since(start).Seconds())
+		apiResponseSize.WithLabelValues(c.target, endpoint).Observe(float64(n))
+	}}
+	return resp, nil
+}
+
+type countingBody struct {
+	io.ReadCloser
+	n    int
+	once sync.Once
+	done func(n int)
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += n
+	return n, err
+}
+
+func (b *countingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() { b.done(b.n) })
+	return err
+}
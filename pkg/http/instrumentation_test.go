@@ -0,0 +1,46 @@
+// Copyright 2025 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestInstrumentedClient(t *testing.T) {
+	tgt := url.URL{Scheme: "https", Host: "instrumented"}
+	ic := &instrumentedClient{target: tgt.String(), next: &fakeHTTPClient{200, `{ "data": "test" }`}}
+	c, _ := newFortiTokenClient(context.Background(), tgt, ic, "TEST-TOKEN")
+	var v struct{ Data string }
+	if err := c.Get("api/v2/monitor/system/status", "", &v); err != nil {
+		t.Fatalf("Get() error %v", err)
+	}
+	ic.next = &fakeHTTPClient{403, `{}`}
+	if err := c.Get("api/v2/monitor/system/status", "", &v); err == nil {
+		t.Fatalf("Get() expected error for 403")
+	}
+
+	for code, exp := range map[string]float64{"200": 1, "403": 1} {
+		got := testutil.ToFloat64(apiRequests.WithLabelValues("https://instrumented", "/api/v2/monitor/system/status", code))
+		if got != exp {
+			t.Errorf("requests{code=%q} = %v, expected %v", code, got, exp)
+		}
+	}
+	if n := testutil.CollectAndCount(apiResponseSize, "fortigate_exporter_api_response_size_bytes"); n == 0 {
+		t.Errorf("expected response size to be observed")
+	}
+}
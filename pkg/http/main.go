@@ -18,7 +18,7 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"time"
@@ -30,7 +30,30 @@ type FortiHTTP interface {
 	Get(path string, query string, obj interface{}) error
 }
 
-func NewFortiClient(ctx context.Context, tgt url.URL, hc *http.Client, aConfig config.FortiExporterConfig) (FortiHTTP, error) {
+type scopedClient interface {
+	withLogger(*slog.Logger) FortiHTTP
+	withContext(context.Context) FortiHTTP
+}
+
+// WithLogger returns a client that attaches logger to its log records.
+// Clients that do not log are returned as-is.
+func WithLogger(c FortiHTTP, logger *slog.Logger) FortiHTTP {
+	if sc, ok := c.(scopedClient); ok {
+		return sc.withLogger(logger)
+	}
+	return c
+}
+
+// WithContext returns a client that issues its requests, and starts its
+// trace spans, under ctx. Clients without a context are returned as-is.
+func WithContext(c FortiHTTP, ctx context.Context) FortiHTTP {
+	if sc, ok := c.(scopedClient); ok {
+		return sc.withContext(ctx)
+	}
+	return c
+}
+
+func NewFortiClient(ctx context.Context, tgt url.URL, hc HTTPClient, aConfig config.FortiExporterConfig) (FortiHTTP, error) {
 
 	auth, ok := aConfig.AuthKeys[config.Target(tgt.String())]
 	if !ok {
@@ -53,8 +76,7 @@ func NewFortiClient(ctx context.Context, tgt url.URL, hc *http.Client, aConfig c
 func Configure(config config.FortiExporterConfig) error {
 	roots, err := x509.SystemCertPool()
 	if err != nil {
-		log.Fatalf("Unable to fetch system CA store: %v", err)
-		return err
+		return fmt.Errorf("unable to fetch system CA store: %w", err)
 	}
 	for _, cert := range config.TlsExtraCAs {
 
@@ -66,7 +88,12 @@ func Configure(config config.FortiExporterConfig) error {
 	if config.TLSInsecure {
 		tc.InsecureSkipVerify = true
 	}
-	http.DefaultTransport.(*http.Transport).TLSHandshakeTimeout = time.Duration(config.TLSTimeout) * time.Second
-	http.DefaultTransport.(*http.Transport).TLSClientConfig = tc
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.TLSHandshakeTimeout = time.Duration(config.TLSTimeout) * time.Second
+	t.TLSClientConfig = tc
+	t.IdleConnTimeout = time.Duration(config.IdleTimeout) * time.Second
+	// FortiOS negotiates HTTP/2 via ALPN on recent releases, older ones fall back to HTTP/1.1
+	t.ForceAttemptHTTP2 = true
+	pool.reset(t, config.MaxRequests)
 	return nil
 }
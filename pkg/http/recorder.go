@@ -0,0 +1,236 @@
+// Copyright 2025 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// recordingClient stores every API response it sees as a jsonnet fixture
+// in the format used by the probe tests, i.e. a "# path?query" header line
+// followed by the JSON document.
+type recordingClient struct {
+	next   FortiHTTP
+	dir    string
+	redact *redactor
+}
+
+// NewRecordingClient wraps c so that all responses are written to dir.
+// If redact is set, serial numbers, IP addresses, user names and secrets are
+// replaced by stable placeholders before writing.
+func NewRecordingClient(c FortiHTTP, dir string, redact bool) (FortiHTTP, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	rc := &recordingClient{next: c, dir: dir}
+	if redact {
+		rc.redact = newRedactor()
+	}
+	return rc, nil
+}
+
+func (c *recordingClient) Get(path string, query string, obj interface{}) error {
+	var raw json.RawMessage
+	if err := c.next.Get(path, query, &raw); err != nil {
+		return err
+	}
+	if err := c.write(path, query, raw); err != nil {
+		// A failed recording must not fail the probe
+		slog.Warn("Failed to record API response", "endpoint", path, "err", err)
+	}
+	return json.Unmarshal(raw, obj)
+}
+
+func (c *recordingClient) write(path string, query string, raw json.RawMessage) error {
+	var doc interface{}
+	d := json.NewDecoder(bytes.NewReader(raw))
+	d.UseNumber()
+	if err := d.Decode(&doc); err != nil {
+		return err
+	}
+	if c.redact != nil {
+		doc = c.redact.value("", doc)
+	}
+	b, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	header := path
+	if query != "" {
+		header += "?" + query
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# %s\n", header)
+	buf.Write(b)
+	buf.WriteString("\n")
+	return os.WriteFile(filepath.Join(c.dir, FixtureName(path, query)), buf.Bytes(), 0o644)
+}
+
+func (c *recordingClient) withLogger(logger *slog.Logger) FortiHTTP {
+	n := *c
+	n.next = WithLogger(c.next, logger)
+	return &n
+}
+
+func (c *recordingClient) withContext(ctx context.Context) FortiHTTP {
+	n := *c
+	n.next = WithContext(c.next, ctx)
+	return &n
+}
+
+var fixtureNameCleaner = regexp.MustCompile(`[^a-zA-Z0-9_.]+`)
+
+// FixtureName returns the file name a response for path and query is recorded under
+func FixtureName(path string, query string) string {
+	name := strings.TrimPrefix(strings.Trim(path, "/"), "api/v2/")
+	if query != "" {
+		name += "_" + strings.ReplaceAll(query, "*", "all")
+	}
+	name = strings.Trim(fixtureNameCleaner.ReplaceAllString(name, "-"), "-")
+	return name + ".jsonnet"
+}
+
+var ipv4Pattern = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
+
+// redactor replaces sensitive values with placeholders. The same input always
+// maps to the same placeholder so that relations inside a recording survive.
+type redactor struct {
+	mu      sync.Mutex
+	mapping map[string]string
+	counts  map[string]int
+}
+
+func newRedactor() *redactor {
+	return &redactor{mapping: map[string]string{}, counts: map[string]int{}}
+}
+
+func (r *redactor) placeholder(kind string, v string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := kind + "\x00" + v
+	if p, ok := r.mapping[key]; ok {
+		return p
+	}
+	r.counts[kind]++
+	n := r.counts[kind]
+	var p string
+	switch kind {
+	case "ipv4":
+		// RFC 5737 documentation ranges
+		p = fmt.Sprintf("198.51.%d.%d", (n/254)%256, n%254+1)
+	case "ipv6":
+		p = fmt.Sprintf("2001:db8::%x", n)
+	case "serial":
+		p = fmt.Sprintf("FGT%013d", n)
+	default:
+		p = fmt.Sprintf("%s%d", kind, n)
+	}
+	r.mapping[key] = p
+	return p
+}
+
+func isSecretKey(k string) bool {
+	for _, s := range []string{"token", "password", "passwd", "secret", "psk", "apikey", "api_key"} {
+		if strings.Contains(k, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func isSerialKey(k string) bool {
+	switch k {
+	case "serial", "sn", "serial_no", "serial_number", "serialno", "wtp_id":
+		return true
+	}
+	return false
+}
+
+func isUserKey(k string) bool {
+	switch k {
+	case "user", "username", "user_name", "admin", "login_name", "auth_user":
+		return true
+	}
+	return false
+}
+
+func (r *redactor) value(key string, v interface{}) interface{} {
+	k := strings.ToLower(key)
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for mk, mv := range t {
+			t[mk] = r.value(mk, mv)
+		}
+		return t
+	case []interface{}:
+		for i, e := range t {
+			t[i] = r.value(key, e)
+		}
+		return t
+	case string:
+		switch {
+		case t == "":
+			return t
+		case isSecretKey(k):
+			return "REDACTED"
+		case isSerialKey(k):
+			return r.placeholder("serial", t)
+		case isUserKey(k):
+			return r.placeholder("user", t)
+		}
+		return r.addresses(t)
+	}
+	return v
+}
+
+// addresses replaces IPv4 and IPv6 addresses found in s
+func (r *redactor) addresses(s string) string {
+	s = ipv4Pattern.ReplaceAllStringFunc(s, func(m string) string {
+		if ip := net.ParseIP(m); ip == nil || ip.IsUnspecified() || ip.IsLoopback() || isMask(ip) {
+			return m
+		}
+		return r.placeholder("ipv4", m)
+	})
+	if strings.Count(s, ":") >= 2 {
+		addr, prefix, found := strings.Cut(s, "/")
+		if ip := net.ParseIP(addr); ip != nil && ip.To4() == nil && !ip.IsUnspecified() && !ip.IsLoopback() && !ip.IsLinkLocalUnicast() {
+			s = r.placeholder("ipv6", addr)
+			if found {
+				s += "/" + prefix
+			}
+		}
+	}
+	return s
+}
+
+// isMask reports whether ip looks like a netmask, which is not sensitive
+func isMask(ip net.IP) bool {
+	ip4 := ip.To4()
+	if ip4 == nil {
+		return false
+	}
+	ones, bits := net.IPMask(ip4).Size()
+	return bits != 0 && ones > 0
+}
@@ -0,0 +1,70 @@
+// Copyright 2025 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFixtureName(t *testing.T) {
+	for _, tc := range []struct{ path, query, exp string }{
+		{"api/v2/monitor/system/status", "", "monitor-system-status.jsonnet"},
+		{"api/v2/monitor/firewall/policy/select", "vdom=*&ip_version=ipv4", "monitor-firewall-policy-select_vdom-all-ip_version-ipv4.jsonnet"},
+	} {
+		if got := FixtureName(tc.path, tc.query); got != tc.exp {
+			t.Errorf("FixtureName(%q, %q) = %q, expected %q", tc.path, tc.query, got, tc.exp)
+		}
+	}
+}
+
+func TestRecordingClient(t *testing.T) {
+	body := `{"serial":"FG100FTK19000001","results":[{"ip":"10.1.2.3","netmask":"255.255.255.0","user":"alice","psk":"hunter2"},{"gateway":"10.1.2.3"}]}`
+	next, _ := newClient(200, body)
+	dir := t.TempDir()
+	c, err := NewRecordingClient(next, dir, true)
+	if err != nil {
+		t.Fatalf("NewRecordingClient() error %v", err)
+	}
+	var v struct{ Serial string }
+	if err := c.Get("api/v2/monitor/system/status", "vdom=root", &v); err != nil {
+		t.Fatalf("Get() error %v", err)
+	}
+	if v.Serial != "FG100FTK19000001" {
+		t.Errorf("Get() returned %q, expected unredacted response", v.Serial)
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, "monitor-system-status_vdom-root.jsonnet"))
+	if err != nil {
+		t.Fatalf("fixture not written: %v", err)
+	}
+	f := string(b)
+	if !strings.HasPrefix(f, "# api/v2/monitor/system/status?vdom=root\n") {
+		t.Errorf("fixture header missing, got %q", strings.SplitN(f, "\n", 2)[0])
+	}
+	for _, secret := range []string{"FG100FTK19000001", "10.1.2.3", "alice", "hunter2"} {
+		if strings.Contains(f, secret) {
+			t.Errorf("fixture contains unredacted %q", secret)
+		}
+	}
+	if !strings.Contains(f, "255.255.255.0") {
+		t.Errorf("fixture should keep netmasks")
+	}
+	// The same address maps to the same placeholder
+	if strings.Count(f, `"198.51.0.2"`) != 2 {
+		t.Errorf("expected consistent placeholder for repeated address:\n%s", f)
+	}
+}
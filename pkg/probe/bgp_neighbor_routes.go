@@ -15,7 +15,6 @@ package probe
 
 import (
 	"fmt"
-	"log"
 
 	"github.com/prometheus-community/fortigate_exporter/internal/config"
 	"github.com/prometheus-community/fortigate_exporter/pkg/http"
@@ -66,7 +65,7 @@ func probeBGPNeighborPathsIPv4(c http.FortiHTTP, meta *TargetMetadata) ([]promet
 	var rs []BGPPaths
 
 	if err := c.Get("api/v2/monitor/router/bgp/paths", fmt.Sprintf("vdom=*&count=%d", MaxBGPPaths), &rs); err != nil {
-		log.Printf("Error: %v", err)
+		meta.Logger().Error("API request failed", "err", err)
 		return nil, false
 	}
 
@@ -75,11 +74,6 @@ func probeBGPNeighborPathsIPv4(c http.FortiHTTP, meta *TargetMetadata) ([]promet
 	srMap := make(map[PathCount]int)
 	sr2Map := make(map[PathCount]int)
 	for _, r := range rs {
-
-		if len(r.Results) > MaxBGPPaths {
-			log.Printf("Error: Received more BGP Paths than maximum (%d > %d) allowed, ignoring metric ...", len(r.Results), MaxBGPPaths)
-			return nil, false
-		}
 		for _, route := range r.Results {
 			sr := PathCount{
 				Source: route.LearnedFrom,
@@ -134,7 +128,7 @@ func probeBGPNeighborPathsIPv6(c http.FortiHTTP, meta *TargetMetadata) ([]promet
 	var rs []BGPPaths
 
 	if err := c.Get("api/v2/monitor/router/bgp/paths6", fmt.Sprintf("vdom=*&count=%d", MaxBGPPaths), &rs); err != nil {
-		log.Printf("Error: %v", err)
+		meta.Logger().Error("API request failed", "err", err)
 		return nil, false
 	}
 
@@ -143,11 +137,6 @@ func probeBGPNeighborPathsIPv6(c http.FortiHTTP, meta *TargetMetadata) ([]promet
 	srMap := make(map[PathCount]int)
 	sr2Map := make(map[PathCount]int)
 	for _, r := range rs {
-
-		if len(r.Results) > MaxBGPPaths {
-			log.Printf("Error: Received more BGP Paths than maximum (%d > %d) allowed, ignoring metric ...", len(r.Results), MaxBGPPaths)
-			return nil, false
-		}
 		for _, route := range r.Results {
 			sr := PathCount{
 				Source: route.LearnedFrom,
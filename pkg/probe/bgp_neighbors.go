@@ -14,7 +14,6 @@
 package probe
 
 import (
-	"log"
 	"strconv"
 
 	"github.com/prometheus-community/fortigate_exporter/pkg/http"
@@ -51,7 +50,7 @@ func probeBGPNeighborsIPv4(c http.FortiHTTP, meta *TargetMetadata) ([]prometheus
 	var rs []BGPNeighborResponse
 
 	if err := c.Get("api/v2/monitor/router/bgp/neighbors", "vdom=*", &rs); err != nil {
-		log.Printf("Error: %v", err)
+		meta.Logger().Error("API request failed", "err", err)
 		return nil, false
 	}
 
@@ -83,7 +82,7 @@ func probeBGPNeighborsIPv6(c http.FortiHTTP, meta *TargetMetadata) ([]prometheus
 	var rs []BGPNeighborResponse
 
 	if err := c.Get("api/v2/monitor/router/bgp/neighbors6", "vdom=*", &rs); err != nil {
-		log.Printf("Error: %v", err)
+		meta.Logger().Error("API request failed", "err", err)
 		return nil, false
 	}
 
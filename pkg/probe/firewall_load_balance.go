@@ -14,7 +14,6 @@
 package probe
 
 import (
-	"log"
 	"math"
 	"strconv"
 
@@ -101,7 +100,7 @@ func probeFirewallLoadBalance(c http.FortiHTTP, meta *TargetMetadata) ([]prometh
 	// Consider implementing pagination to remove this limit of 1000 entries
 	var rs []LoadBalanceResponse
 	if err := c.Get("api/v2/monitor/firewall/load-balance", "vdom=*&start=0&count=1000", &rs); err != nil {
-		log.Printf("Error: %v", err)
+		meta.Logger().Error("API request failed", "err", err)
 		return nil, false
 	}
 
@@ -140,7 +139,7 @@ func probeFirewallLoadBalance(c http.FortiHTTP, meta *TargetMetadata) ([]prometh
 					// NaN
 				default:
 					if realServerRTTValueInMs, err := strconv.ParseFloat(realServer.RTT, 64); err != nil {
-						log.Printf("Failed to parse RTT value: %v", err)
+						meta.Logger().Warn("Failed to parse RTT value", "err", err)
 					} else {
 						realServerRTTValue = realServerRTTValueInMs / 1000
 					}
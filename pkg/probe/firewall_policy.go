@@ -15,7 +15,7 @@ package probe
 
 import (
 	"fmt"
-	"log"
+	"time"
 
 	"github.com/prometheus-community/fortigate_exporter/internal/version"
 	"github.com/prometheus-community/fortigate_exporter/pkg/http"
@@ -75,14 +75,19 @@ func probeFirewallPolicies(c http.FortiHTTP, meta *TargetMetadata) ([]prometheus
 
 	// NOTE: ip_version=ipv4 is a no-op if combined policies are not active
 	if err := c.Get("api/v2/monitor/firewall/policy/select", "vdom=*&ip_version=ipv4", &ps4); err != nil {
-		log.Printf("Error: %v", err)
+		meta.Logger().Error("API request failed", "err", err)
+		return nil, false
+	}
+
+	if len(ps4) == 0 {
+		meta.Logger().Error("No VDOMs returned by policy statistics")
 		return nil, false
 	}
 
 	combined := false
 	maj, min, ok := version.ParseVersion(ps4[0].Version)
 	if !ok {
-		log.Printf("Could not parse version number %q", ps4[0].Version)
+		meta.Logger().Error("Could not parse version number", "version", ps4[0].Version)
 		return nil, false
 	}
 	// If we are at 6.4 or later we use combined policies
@@ -90,15 +95,17 @@ func probeFirewallPolicies(c http.FortiHTTP, meta *TargetMetadata) ([]prometheus
 		combined = true
 	}
 
+	// The IPv4 policies are still returned if the IPv6 ones cannot be fetched
+	ok = true
 	if !combined {
 		if err := c.Get("api/v2/monitor/firewall/policy6/select", "vdom=*", &ps6); err != nil {
-			log.Printf("Error: %v", err)
-			return nil, false
+			meta.Logger().Error("API request failed", "err", err)
+			ok, ps6 = false, nil
 		}
 	} else {
 		if err := c.Get("api/v2/monitor/firewall/policy/select", "vdom=*&ip_version=ipv6", &ps6); err != nil {
-			log.Printf("Error: %v", err)
-			return nil, false
+			meta.Logger().Error("API request failed", "err", err)
+			ok, ps6 = false, nil
 		}
 	}
 
@@ -120,13 +127,13 @@ func probeFirewallPolicies(c http.FortiHTTP, meta *TargetMetadata) ([]prometheus
 	query := "vdom=*&policyid|name|uuid|action|status"
 
 	if err := c.Get("api/v2/cmdb/firewall/policy", query, &pc); err != nil {
-		log.Printf("Error: %v", err)
+		meta.Logger().Error("API request failed", "err", err)
 		return nil, false
 	}
-	if !combined {
+	if !combined && ps6 != nil {
 		if err := c.Get("api/v2/cmdb/firewall/policy6", query, &pc6); err != nil {
-			log.Printf("Error: %v", err)
-			return nil, false
+			meta.Logger().Error("API request failed", "err", err)
+			ok, ps6 = false, nil
 		}
 	}
 
@@ -153,16 +160,23 @@ func probeFirewallPolicies(c http.FortiHTTP, meta *TargetMetadata) ([]prometheus
 		if s.ID > 0 {
 			c, ok := pcMap[s.UUID]
 			if !ok {
-				log.Printf("Warning: Failed to map %q to policy config - this should not happen", s.UUID)
+				meta.Logger().Warn("Failed to map policy to policy config - this should not happen", "uuid", s.UUID)
 				name = "<UNKNOWN>"
 			} else {
 				name = c.Name
 			}
 		}
+		counter := func(desc *prometheus.Desc, value float64) prometheus.Metric {
+			// The statistics of a policy start counting when it is first used
+			if s.FirstUsed > 0 {
+				return prometheus.MustNewConstMetricWithCreatedTimestamp(desc, prometheus.CounterValue, value, time.Unix(int64(s.FirstUsed), 0), ps.VDOM, proto, name, s.UUID, id)
+			}
+			return prometheus.MustNewConstMetric(desc, prometheus.CounterValue, value, ps.VDOM, proto, name, s.UUID, id)
+		}
 		m := []prometheus.Metric{
-			prometheus.MustNewConstMetric(mHitCount, prometheus.CounterValue, s.HitCount, ps.VDOM, proto, name, s.UUID, id),
-			prometheus.MustNewConstMetric(mBytes, prometheus.CounterValue, s.Bytes, ps.VDOM, proto, name, s.UUID, id),
-			prometheus.MustNewConstMetric(mPackets, prometheus.CounterValue, s.Packets, ps.VDOM, proto, name, s.UUID, id),
+			counter(mHitCount, s.HitCount),
+			counter(mBytes, s.Bytes),
+			counter(mPackets, s.Packets),
 			prometheus.MustNewConstMetric(mActiveSessions, prometheus.GaugeValue, s.ActiveSessions, ps.VDOM, proto, name, s.UUID, id),
 		}
 		return m
@@ -181,5 +195,5 @@ func probeFirewallPolicies(c http.FortiHTTP, meta *TargetMetadata) ([]prometheus
 		}
 	}
 
-	return m, true
+	return m, ok
 }
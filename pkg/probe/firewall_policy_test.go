@@ -14,6 +14,7 @@
 package probe
 
 import (
+	"errors"
 	"strings"
 	"testing"
 
@@ -131,3 +132,28 @@ func TestFirewallPolicies(t *testing.T) {
 		t.Fatalf("metric compare: err %v", err)
 	}
 }
+
+func TestFirewallPoliciesPartial(t *testing.T) {
+	c := newFakeClient()
+	c.prepare("api/v2/monitor/firewall/policy/select?ip_version=ipv4", "testdata/fw-policy-v4.jsonnet")
+	c.prepareError("api/v2/monitor/firewall/policy/select?ip_version=ipv6", errors.New("connection reset"))
+	c.prepare("api/v2/cmdb/firewall/policy", "testdata/fw-policy-config.jsonnet")
+	m, ok := probeFirewallPolicies(c, &TargetMetadata{VersionMajor: 7, VersionMinor: 4})
+	if ok {
+		t.Errorf("probeFirewallPolicies() returned success with failed IPv6 request")
+	}
+	r := prometheus.NewPedanticRegistry()
+	r.MustRegister(&testProbeCollector{metrics: m})
+
+	em := `
+	# HELP fortigate_policy_active_sessions Number of active sessions for a policy
+	# TYPE fortigate_policy_active_sessions gauge
+	fortigate_policy_active_sessions{id="0",name="Implicit Deny",protocol="ipv4",uuid="",vdom="FG-traffic"} 0
+	fortigate_policy_active_sessions{id="0",name="Implicit Deny",protocol="ipv4",uuid="",vdom="root"} 0
+	fortigate_policy_active_sessions{id="1",name="",protocol="ipv4",uuid="078f184c-9e9d-51ea-9fbb-66c20957b9c0",vdom="FG-traffic"} 2
+	fortigate_policy_active_sessions{id="2",name="ping",protocol="ipv4",uuid="24843c52-9e9d-51ea-b838-3500a9e54b2e",vdom="FG-traffic"} 0
+	`
+	if err := testutil.GatherAndCompare(r, strings.NewReader(em), "fortigate_policy_active_sessions"); err != nil {
+		t.Fatalf("metric compare: err %v", err)
+	}
+}
@@ -0,0 +1,249 @@
+// Copyright 2025 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package probe
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// Output formats of ProbeHandler, selected by the format parameter or the Accept header
+const (
+	formatPrometheus  = "prometheus"
+	formatOpenMetrics = "openmetrics"
+	formatJSON        = "json"
+)
+
+// formatTypes are the exposition formats the Accept header is replaced with
+// when the format parameter is given
+var formatTypes = map[string]expfmt.FormatType{
+	formatPrometheus:  expfmt.TypeTextPlain,
+	formatOpenMetrics: expfmt.TypeOpenMetrics,
+}
+
+// metricUnits are the units written as # UNIT in OpenMetrics output, in the
+// JSON output and in pushed metrics, taken from the suffix of the metric name
+var metricUnits = []string{"seconds", "bytes", "ratio", "celsius", "volts", "watt", "rpm", "bps", "amperes", "dbm"}
+
+// negotiateFormat returns the output format requested by r. An empty format
+// means the exposition format is left to promhttp.
+func negotiateFormat(r *http.Request) (string, error) {
+	switch f := r.URL.Query().Get("format"); f {
+	case formatPrometheus, formatOpenMetrics, formatJSON:
+		return f, nil
+	case "":
+	default:
+		return "", fmt.Errorf("unknown format %q, must be one of %s, %s or %s", f, formatPrometheus, formatOpenMetrics, formatJSON)
+	}
+	if preferredMediaType(r.Header.Get("Accept")) == "application/json" {
+		return formatJSON, nil
+	}
+	if expfmt.NegotiateIncludingOpenMetrics(r.Header).FormatType() == expfmt.TypeOpenMetrics {
+		return formatOpenMetrics, nil
+	}
+	return "", nil
+}
+
+// preferredMediaType returns the media type with the highest quality in an
+// Accept header, the first one wins on equal quality
+func preferredMediaType(accept string) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(accept, ",") {
+		mt, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if q > bestQ {
+			best, bestQ = mt, q
+		}
+	}
+	return best
+}
+
+// MetricUnit returns the unit a metric family is measured in, derived from its name
+func MetricUnit(mf *dto.MetricFamily) string {
+	name := mf.GetName()
+	if mf.GetType() == dto.MetricType_COUNTER {
+		name = strings.TrimSuffix(name, "_total")
+	}
+	for _, u := range metricUnits {
+		if strings.HasSuffix(name, "_"+u) {
+			return u
+		}
+	}
+	return ""
+}
+
+// writeOpenMetrics writes the metrics of registry in the OpenMetrics format
+// negotiated for r, including units and the created timestamps of counters.
+// promhttp cannot be used as it never writes units.
+func writeOpenMetrics(w http.ResponseWriter, r *http.Request, registry *prometheus.Registry) error {
+	mfs, err := registry.Gather()
+	if err != nil {
+		return err
+	}
+	format := expfmt.NegotiateIncludingOpenMetrics(r.Header)
+	w.Header().Set("Content-Type", string(format))
+	var out io.Writer = w
+	if acceptsGzip(r) {
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		defer gz.Close()
+		out = gz
+	}
+	enc := expfmt.NewEncoder(out, format, expfmt.WithUnit(), expfmt.WithCreatedLines())
+	for _, mf := range mfs {
+		if u := MetricUnit(mf); u != "" {
+			mf.Unit = &u
+		}
+		if err := enc.Encode(mf); err != nil {
+			return err
+		}
+	}
+	if closer, ok := enc.(expfmt.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// acceptsGzip reports whether the Accept-Encoding header of r allows gzip
+func acceptsGzip(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		if strings.TrimSpace(coding) == "gzip" && strings.ReplaceAll(params, " ", "") != "q=0" {
+			return true
+		}
+	}
+	return false
+}
+
+type jsonResponse struct {
+	Target          string               `json:"target"`
+	Success         bool                 `json:"success"`
+	DurationSeconds float64              `json:"duration_seconds"`
+	Probes          map[string]jsonProbe `json:"probes"`
+}
+
+type jsonProbe struct {
+	Success         bool         `json:"success"`
+	Stale           bool         `json:"stale,omitempty"`
+	DurationSeconds float64      `json:"duration_seconds"`
+	Metrics         []jsonMetric `json:"metrics"`
+}
+
+type jsonMetric struct {
+	Name    string       `json:"name"`
+	Help    string       `json:"help"`
+	Type    string       `json:"type"`
+	Unit    string       `json:"unit,omitempty"`
+	Samples []jsonSample `json:"samples"`
+}
+
+type jsonSample struct {
+	Labels  map[string]string `json:"labels,omitempty"`
+	Value   jsonValue         `json:"value"`
+	Created *time.Time        `json:"created,omitempty"`
+}
+
+// jsonValue is a sample value, NaN and infinities are encoded as strings as
+// they have no JSON representation
+type jsonValue float64
+
+func (v jsonValue) MarshalJSON() ([]byte, error) {
+	f := float64(v)
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return json.Marshal(strconv.FormatFloat(f, 'f', -1, 64))
+	}
+	return json.Marshal(f)
+}
+
+// writeJSON writes the metrics of pc as JSON, keyed by probe
+func writeJSON(w http.ResponseWriter, target string, success bool, duration time.Duration, pc *ProbeCollector) error {
+	resp := jsonResponse{
+		Target:          target,
+		Success:         success,
+		DurationSeconds: duration.Seconds(),
+		Probes:          map[string]jsonProbe{},
+	}
+	for _, r := range pc.Results() {
+		registry := prometheus.NewRegistry()
+		if err := registry.Register(&ProbeCollector{metrics: r.metrics}); err != nil {
+			return err
+		}
+		mfs, err := registry.Gather()
+		if err != nil {
+			return fmt.Errorf("probe %s: %w", r.Name, err)
+		}
+		p := jsonProbe{
+			Success:         r.Success,
+			Stale:           r.Stale,
+			DurationSeconds: r.Duration.Seconds(),
+			Metrics:         []jsonMetric{},
+		}
+		for _, mf := range mfs {
+			jm := jsonMetric{
+				Name: mf.GetName(),
+				Help: mf.GetHelp(),
+				Type: strings.ToLower(mf.GetType().String()),
+				Unit: MetricUnit(mf),
+			}
+			for _, m := range mf.GetMetric() {
+				s := jsonSample{}
+				if len(m.GetLabel()) != 0 {
+					s.Labels = map[string]string{}
+					for _, l := range m.GetLabel() {
+						s.Labels[l.GetName()] = l.GetValue()
+					}
+				}
+				switch {
+				case m.Counter != nil:
+					s.Value = jsonValue(m.GetCounter().GetValue())
+					if ct := m.GetCounter().GetCreatedTimestamp(); ct != nil {
+						t := ct.AsTime().UTC()
+						s.Created = &t
+					}
+				case m.Gauge != nil:
+					s.Value = jsonValue(m.GetGauge().GetValue())
+				default:
+					s.Value = jsonValue(m.GetUntyped().GetValue())
+				}
+				jm.Samples = append(jm.Samples, s)
+			}
+			p.Metrics = append(p.Metrics, jm)
+		}
+		resp.Probes[r.Name] = p
+	}
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(resp)
+}
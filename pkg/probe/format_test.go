@@ -0,0 +1,140 @@
+// Copyright 2025 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package probe
+
+import (
+	"compress/gzip"
+	"io"
+	"math"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/prometheus-community/fortigate_exporter/internal/config"
+)
+
+func TestNegotiateFormat(t *testing.T) {
+	tests := []struct {
+		query  string
+		accept string
+		want   string
+	}{
+		{query: "", accept: "", want: ""},
+		{query: "", accept: "text/plain;version=0.0.4", want: ""},
+		{query: "", accept: "application/json", want: formatJSON},
+		{query: "", accept: "text/plain;q=0.5,application/json", want: formatJSON},
+		{query: "", accept: "application/json;q=0.1,text/plain", want: ""},
+		// What Prometheus sends by default
+		{query: "", accept: "application/openmetrics-text;version=1.0.0;escaping=allow-utf-8;q=0.6,application/openmetrics-text;version=0.0.1;q=0.5,text/plain;version=1.0.0;escaping=allow-utf-8;q=0.4,text/plain;version=0.0.4;q=0.3,*/*;q=0.2", want: formatOpenMetrics},
+		{query: "format=json", accept: "application/openmetrics-text;version=1.0.0", want: formatJSON},
+		{query: "format=openmetrics", accept: "", want: formatOpenMetrics},
+		{query: "format=prometheus", accept: "application/openmetrics-text;version=1.0.0", want: formatPrometheus},
+	}
+	for _, tc := range tests {
+		r := httptest.NewRequest("GET", "/probe?"+tc.query, nil)
+		if tc.accept != "" {
+			r.Header.Set("Accept", tc.accept)
+		}
+		got, err := negotiateFormat(r)
+		if err != nil {
+			t.Errorf("negotiateFormat(%q, %q) returned error: %v", tc.query, tc.accept, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("negotiateFormat(%q, %q) = %q, want %q", tc.query, tc.accept, got, tc.want)
+		}
+	}
+
+	if _, err := negotiateFormat(httptest.NewRequest("GET", "/probe?format=xml", nil)); err == nil {
+		t.Errorf("negotiateFormat() accepted unknown format")
+	}
+}
+
+func TestJSONValue(t *testing.T) {
+	for v, want := range map[float64]string{
+		1.5:          "1.5",
+		math.NaN():   `"NaN"`,
+		math.Inf(1):  `"+Inf"`,
+		math.Inf(-1): `"-Inf"`,
+	} {
+		got, err := jsonValue(v).MarshalJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("jsonValue(%v) = %s, want %s", v, got, want)
+		}
+	}
+}
+
+func TestProbeHandlerUnknownFormat(t *testing.T) {
+	config.MustReInit()
+	w := httptest.NewRecorder()
+	ProbeHandler(w, httptest.NewRequest("GET", "/probe?target=https://fortigate&format=xml", nil))
+	if w.Code != 400 {
+		t.Errorf("ProbeHandler() returned %d, want 400", w.Code)
+	}
+}
+
+func TestProbeHandlerOpenMetrics(t *testing.T) {
+	srv := newGoldenMock(t, "7.4")
+	configureGolden(t, srv, goldenCase{authEntry: goldenSmallProbes})
+
+	tests := []struct {
+		name        string
+		query       string
+		accept      string
+		contentType string
+	}{
+		{
+			name:        "format parameter",
+			query:       "&format=openmetrics",
+			contentType: "application/openmetrics-text; version=1.0.0; charset=utf-8; escaping=underscores",
+		},
+		{
+			name:        "accept header",
+			accept:      "application/openmetrics-text;version=1.0.0;escaping=allow-utf-8",
+			contentType: "application/openmetrics-text; version=1.0.0; charset=utf-8; escaping=allow-utf-8",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/probe?target="+url.QueryEscape(srv.URL)+tc.query, nil)
+			r.Header.Set("Accept", tc.accept)
+			r.Header.Set("Accept-Encoding", "gzip")
+			w := httptest.NewRecorder()
+			ProbeHandler(w, r)
+			if got := w.Header().Get("Content-Type"); got != tc.contentType {
+				t.Errorf("Content-Type = %q, want %q", got, tc.contentType)
+			}
+			if got := w.Header().Get("Content-Encoding"); got != "gzip" {
+				t.Fatalf("Content-Encoding = %q, want gzip", got)
+			}
+			zr, err := gzip.NewReader(w.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, err := io.ReadAll(zr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range []string{"# UNIT fortigate_lb_real_server_rtt_seconds seconds", "fortigate_policy_hit_count_created{", "# EOF"} {
+				if !strings.Contains(string(body), want) {
+					t.Errorf("body does not contain %q:\n%s", want, body)
+				}
+			}
+		})
+	}
+}
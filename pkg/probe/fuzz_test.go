@@ -0,0 +1,74 @@
+// Copyright 2025 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package probe
+
+import (
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-jsonnet"
+	"github.com/prometheus-community/fortigate_exporter/internal/config"
+	"github.com/prometheus-community/fortigate_exporter/pkg/http"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// FuzzProbes feeds arbitrary JSON to every registered probe. The probes are
+// called directly, without runProbe, so that any panic is reported.
+func FuzzProbes(f *testing.F) {
+	config.MustReInit()
+	files, _ := filepath.Glob("testdata/*.jsonnet")
+	vm := jsonnet.MakeVM()
+	for _, file := range files {
+		out, err := vm.EvaluateFile(file)
+		if err != nil {
+			f.Fatalf("Failed to evaluate jsonnet %q: %v", file, err)
+		}
+		f.Add([]byte(out))
+	}
+	for _, seed := range []string{`null`, `{}`, `[]`, `[{}]`, `{"results":[]}`, `[{"results":{}}]`, `{"results":{"cpu":[],"mem":[]}}`} {
+		f.Add([]byte(seed))
+	}
+
+	logger := slog.Default()
+	defer slog.SetDefault(logger)
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, meta := range []*TargetMetadata{{VersionMajor: 6, VersionMinor: 2}, {VersionMajor: 7, VersionMinor: 4}} {
+			for _, p := range probeList {
+				c := newFakeClient()
+				c.prepareFallback(data)
+				p.function(c, meta)
+			}
+		}
+	})
+}
+
+func TestRunProbeRecovers(t *testing.T) {
+	p := probeDetailedFunc{"Test/Panic", func(c http.FortiHTTP, meta *TargetMetadata) ([]prometheus.Metric, bool) {
+		var empty []int
+		_ = empty[1]
+		return nil, true
+	}}
+	m, ok := runProbe(p, newFakeClient(), &TargetMetadata{logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+	if ok || m != nil {
+		t.Errorf("runProbe() = %v, %v, expected failure", m, ok)
+	}
+	if v := testutil.ToFloat64(probePanics.WithLabelValues("Test/Panic")); v != 1 {
+		t.Errorf("probe panics = %v, expected 1", v)
+	}
+}
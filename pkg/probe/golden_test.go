@@ -0,0 +1,239 @@
+// Copyright 2025 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package probe
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	nethttp "net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prometheus-community/fortigate_exporter/internal/exportertest"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files in testdata/golden")
+
+type goldenCase struct {
+	name    string
+	version string
+	// authEntry is appended to the target in the auth file
+	authEntry string
+	// params are added to the probe request, {{target}} is replaced by the mock URL
+	params string
+	// format is passed as format parameter, the golden file of json is a .json file
+	format string
+}
+
+// file returns the name of the golden file of the case in testdata/golden
+func (tc goldenCase) file() string {
+	if tc.format == formatJSON {
+		return tc.name + ".json"
+	}
+	return tc.name + ".prom"
+}
+
+// goldenCases cover one version on each side of the version checks of the
+// probes: before 6.4 (no combined policies nor load balancer monitor), 6.4
+// and 7.x (BGP, OSPF and transceiver probes)
+var goldenCases = []goldenCase{
+	{name: "6.2", version: "6.2"},
+	{name: "6.4", version: "6.4"},
+	{name: "7.4", version: "7.4"},
+	{
+		name:    "7.4-include-exclude",
+		version: "7.4",
+		authEntry: `
+  probes:
+    include:
+      - System
+      - Firewall/Policies
+    exclude:
+      - System/SensorInfo
+      - System/HA`,
+	},
+	{
+		name:    "7.4-dynamic-profile",
+		version: "7.4",
+		params:  "&token=golden-token&profile=golden-profile",
+	},
+	{
+		name:      "7.4-openmetrics",
+		version:   "7.4",
+		authEntry: goldenSmallProbes,
+		format:    formatOpenMetrics,
+	},
+	{
+		name:      "7.4-json",
+		version:   "7.4",
+		authEntry: goldenSmallProbes,
+		format:    formatJSON,
+	},
+	{
+		name:    "7.4-labels",
+		version: "7.4",
+		authEntry: goldenSmallProbes + `
+  labels:
+    site: zrh1
+    vdom: shared`,
+	},
+	{
+		name:    "7.4-dynamic-labels",
+		version: "7.4",
+		params:  "&token=golden-token&profile=golden-labels-profile",
+	},
+	{
+		name:    "7.4-relabel",
+		version: "7.4",
+		authEntry: goldenSmallProbes + `
+  metric_relabel_configs:
+    - source_labels: [__name__]
+      regex: fortigate_policy_(bytes|packets)_total
+      action: drop
+    - regex: uuid
+      action: labeldrop
+    - source_labels: [vdom, id]
+      regex: FG-traffic;(.*)
+      target_label: policy
+      replacement: traffic-$1
+  series_limits:
+    Firewall/LoadBalance: 5`,
+	},
+}
+
+// goldenSmallProbes keeps the output of the format cases short
+const goldenSmallProbes = `
+  probes:
+    include:
+      - System/Status
+      - Firewall/Policies
+      - Firewall/LoadBalance`
+
+// newGoldenMock starts a mock FortiGate serving the fixtures for version
+func newGoldenMock(t *testing.T, version string) *httptest.Server {
+	return exportertest.Serve(t, exportertest.Fixtures(t, version), "golden-token")
+}
+
+// configureGolden points the global exporter configuration at srv
+func configureGolden(t *testing.T, srv *httptest.Server, tc goldenCase) {
+	auth := "golden-profile:\n  probes:\n    exclude:\n      - Wifi\n      - Switch\n"
+	auth += "golden-labels-profile:" + goldenSmallProbes + "\n  labels:\n    site: ber2\n    name: fw-ber2\n  label_prefix: inventory_\n"
+	if tc.params == "" {
+		auth += fmt.Sprintf("%q:\n  token: golden-token%s\n", srv.URL, tc.authEntry)
+	}
+	exportertest.Configure(t, auth, nil)
+}
+
+// normalizeExposition drops the values that change between runs
+func normalizeExposition(b []byte, target string) string {
+	var out []string
+	for _, l := range strings.Split(strings.ReplaceAll(string(b), target, "<target>"), "\n") {
+		if strings.HasPrefix(l, "probe_duration_seconds ") {
+			l = "probe_duration_seconds <elided>"
+		}
+		if i := strings.Index(l, `"duration_seconds": `); i >= 0 {
+			l = l[:i] + `"duration_seconds": "<elided>",`
+		}
+		out = append(out, l)
+	}
+	return strings.Join(out, "\n")
+}
+
+func TestProbeHandlerGolden(t *testing.T) {
+	for _, tc := range goldenCases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := newGoldenMock(t, tc.version)
+			configureGolden(t, srv, tc)
+
+			params := tc.params
+			if tc.format != "" {
+				params += "&format=" + tc.format
+			}
+			req := httptest.NewRequest("GET", "/probe?target="+url.QueryEscape(srv.URL)+params, nil)
+			w := httptest.NewRecorder()
+			ProbeHandler(w, req)
+			if w.Code != nethttp.StatusOK {
+				t.Fatalf("ProbeHandler() returned %d: %s", w.Code, w.Body.String())
+			}
+			body, _ := io.ReadAll(w.Body)
+			got := normalizeExposition(body, srv.URL)
+
+			golden := filepath.Join("testdata", "golden", tc.file())
+			if *updateGolden {
+				if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			exp, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("failed to read golden file, run with -update to create it: %v", err)
+			}
+			if got != string(exp) {
+				t.Errorf("exposition differs from %s, run with -update to accept:\n%s", golden, lineDiff(string(exp), got))
+			}
+		})
+	}
+}
+
+// TestGoldenFilesUsed makes sure no golden file is left behind without a case
+// that checks it
+func TestGoldenFilesUsed(t *testing.T) {
+	used := map[string]bool{}
+	for _, tc := range goldenCases {
+		used[tc.file()] = true
+	}
+	files, err := os.ReadDir(filepath.Join("testdata", "golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		if !used[f.Name()] {
+			t.Errorf("testdata/golden/%s is not checked by any golden case", f.Name())
+		}
+	}
+}
+
+// lineDiff lists the lines only present in one of a and b
+func lineDiff(a, b string) string {
+	count := map[string]int{}
+	for _, l := range strings.Split(a, "\n") {
+		count[l]++
+	}
+	for _, l := range strings.Split(b, "\n") {
+		count[l]--
+	}
+	var d []string
+	for _, l := range strings.Split(a, "\n") {
+		if count[l] > 0 {
+			d = append(d, "- "+l)
+			count[l]--
+		}
+	}
+	for _, l := range strings.Split(b, "\n") {
+		if count[l] < 0 {
+			d = append(d, "+ "+l)
+			count[l]++
+		}
+	}
+	return strings.Join(d, "\n")
+}
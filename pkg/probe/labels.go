@@ -0,0 +1,226 @@
+// Copyright 2025 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package probe
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/prometheus-community/fortigate_exporter/internal/config"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
+)
+
+// defaultLabelPrefix is put in front of a target label that collides with a
+// label of the metric
+const defaultLabelPrefix = "target_"
+
+// rebuildMetric returns a const metric with the name, help, type and value of
+// dm and the given labels
+func rebuildMetric(name, help string, dm *dto.Metric, labels []*dto.LabelPair) (prometheus.Metric, error) {
+	names := make([]string, len(labels))
+	values := make([]string, len(labels))
+	for i, l := range labels {
+		names[i], values[i] = l.GetName(), l.GetValue()
+	}
+	desc := prometheus.NewDesc(name, help, names, nil)
+	switch {
+	case dm.Counter != nil:
+		if ct := dm.GetCounter().GetCreatedTimestamp(); ct != nil {
+			return prometheus.NewConstMetricWithCreatedTimestamp(desc, prometheus.CounterValue, dm.GetCounter().GetValue(), ct.AsTime(), values...)
+		}
+		return prometheus.NewConstMetric(desc, prometheus.CounterValue, dm.GetCounter().GetValue(), values...)
+	case dm.Gauge != nil:
+		return prometheus.NewConstMetric(desc, prometheus.GaugeValue, dm.GetGauge().GetValue(), values...)
+	case dm.Untyped != nil:
+		return prometheus.NewConstMetric(desc, prometheus.UntypedValue, dm.GetUntyped().GetValue(), values...)
+	}
+	return nil, fmt.Errorf("unsupported metric type of %s", name)
+}
+
+// withTargetLabels adds the static labels of a target to every metric the
+// way a wrapping registerer does. A label the metric already has is added with
+// prefix instead.
+func withTargetLabels(ms []prometheus.Metric, labels map[string]string, prefix string, logger *slog.Logger) []prometheus.Metric {
+	if len(labels) == 0 {
+		return ms
+	}
+	if prefix == "" {
+		prefix = defaultLabelPrefix
+	}
+	names := make([]string, 0, len(labels))
+	for n := range labels {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	// Metrics are wrapped in groups with the same labels after prefixing
+	type group struct {
+		labels  prometheus.Labels
+		metrics []prometheus.Metric
+	}
+	groups := map[string]*group{}
+	var keys []string
+	out := make([]prometheus.Metric, 0, len(ms))
+	for _, m := range ms {
+		dm := &dto.Metric{}
+		if err := m.Write(dm); err != nil {
+			logger.Error("Failed to add target labels", "err", err)
+			out = append(out, m)
+			continue
+		}
+		have := map[string]bool{}
+		for _, l := range dm.GetLabel() {
+			have[l.GetName()] = true
+		}
+		ls := prometheus.Labels{}
+		key := ""
+		for _, n := range names {
+			ln := n
+			if have[ln] {
+				ln = prefix + n
+			}
+			if have[ln] {
+				logger.Warn("Target label collides with metric label even with prefix, dropping it", "metric", m.Desc().String(), "label", ln)
+				continue
+			}
+			ls[ln] = labels[n]
+			key += ln + "\xff"
+		}
+		g, ok := groups[key]
+		if !ok {
+			g = &group{labels: ls}
+			groups[key] = g
+			keys = append(keys, key)
+		}
+		g.metrics = append(g.metrics, m)
+	}
+
+	ch := make(chan prometheus.Metric, len(ms))
+	for _, k := range keys {
+		prometheus.WrapCollectorWith(groups[k].labels, &ProbeCollector{metrics: groups[k].metrics}).Collect(ch)
+	}
+	close(ch)
+	for m := range ch {
+		out = append(out, m)
+	}
+	return out
+}
+
+// relabel applies the metric_relabel_configs of a target to ms. A series that
+// ends up with the same labels as an earlier one is dropped.
+func relabel(ms []prometheus.Metric, rules []config.RelabelConfig, logger *slog.Logger) []prometheus.Metric {
+	if len(rules) == 0 {
+		return ms
+	}
+	// Gathering gives the name and help of the metrics to rebuild them with
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(&ProbeCollector{metrics: ms}); err != nil {
+		logger.Error("Failed to relabel metrics", "err", err)
+		return nil
+	}
+	mfs, err := registry.Gather()
+	if err != nil {
+		logger.Error("Failed to relabel some metrics, dropping them", "err", err)
+	}
+	out := make([]prometheus.Metric, 0, len(ms))
+	seen := map[string]bool{}
+	duplicates := 0
+	for _, mf := range mfs {
+		for _, dm := range mf.GetMetric() {
+			ls := map[string]string{"__name__": mf.GetName()}
+			for _, l := range dm.GetLabel() {
+				ls[l.GetName()] = l.GetValue()
+			}
+			if !applyRelabel(ls, rules) {
+				continue
+			}
+
+			name := ls["__name__"]
+			delete(ls, "__name__")
+			names := make([]string, 0, len(ls))
+			for n, v := range ls {
+				if v != "" {
+					names = append(names, n)
+				}
+			}
+			sort.Strings(names)
+			key := name
+			pairs := make([]*dto.LabelPair, 0, len(names))
+			for _, n := range names {
+				key += "\xff" + n + "\xff" + ls[n]
+				pairs = append(pairs, &dto.LabelPair{Name: proto.String(n), Value: proto.String(ls[n])})
+			}
+			if seen[key] {
+				duplicates++
+				continue
+			}
+			seen[key] = true
+			nm, err := rebuildMetric(name, mf.GetHelp(), dm, pairs)
+			if err != nil {
+				logger.Error("Failed to relabel metric, dropping it", "metric", name, "err", err)
+				continue
+			}
+			out = append(out, nm)
+		}
+	}
+	if duplicates > 0 {
+		logger.Warn("Relabeling produced duplicate series, dropping them", "count", duplicates)
+	}
+	return out
+}
+
+// applyRelabel applies rules to the labels of a series, it returns false if
+// the series is dropped
+func applyRelabel(ls map[string]string, rules []config.RelabelConfig) bool {
+	for _, r := range rules {
+		values := make([]string, len(r.SourceLabels))
+		for i, n := range r.SourceLabels {
+			values[i] = ls[n]
+		}
+		val := strings.Join(values, r.Separator)
+		re := r.Regexp()
+		switch r.Action {
+		case config.RelabelKeep:
+			if !re.MatchString(val) {
+				return false
+			}
+		case config.RelabelDrop:
+			if re.MatchString(val) {
+				return false
+			}
+		case config.RelabelLabelDrop:
+			for n := range ls {
+				if n != "__name__" && re.MatchString(n) {
+					delete(ls, n)
+				}
+			}
+		case config.RelabelReplace:
+			idx := re.FindStringSubmatchIndex(val)
+			if idx == nil {
+				continue
+			}
+			target := string(re.ExpandString(nil, r.TargetLabel, val, idx))
+			if res := string(re.ExpandString(nil, r.Replacement, val, idx)); res != "" {
+				ls[target] = res
+			} else {
+				delete(ls, target)
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,125 @@
+// Copyright 2025 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package probe
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus-community/fortigate_exporter/internal/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"gopkg.in/yaml.v2"
+)
+
+func TestWithTargetLabels(t *testing.T) {
+	hits := prometheus.NewDesc("fortigate_policy_hit_count_total", "Number of times a policy has been hit", []string{"vdom", "target_vdom"}, nil)
+	info := prometheus.NewDesc("fortigate_version_info", "System version and build information", []string{"version"}, nil)
+	sessions := prometheus.NewDesc("fortigate_vdom_session_count", "Number of sessions", nil, prometheus.Labels{"vdom": "root"})
+	created := time.Unix(1589924891, 0)
+	ms := withTargetLabels([]prometheus.Metric{
+		prometheus.MustNewConstMetricWithCreatedTimestamp(hits, prometheus.CounterValue, 42, created, "root", "x"),
+		prometheus.MustNewConstMetric(info, prometheus.GaugeValue, 1, "v7.4.4"),
+		prometheus.MustNewConstMetric(sessions, prometheus.GaugeValue, 7),
+	}, map[string]string{"site": "zrh1", "vdom": "shared"}, "", slog.Default())
+
+	r := prometheus.NewPedanticRegistry()
+	r.MustRegister(&ProbeCollector{metrics: ms})
+	em := `
+	# HELP fortigate_policy_hit_count_total Number of times a policy has been hit
+	# TYPE fortigate_policy_hit_count_total counter
+	fortigate_policy_hit_count_total{site="zrh1",target_vdom="x",vdom="root"} 42
+	# HELP fortigate_version_info System version and build information
+	# TYPE fortigate_version_info gauge
+	fortigate_version_info{site="zrh1",vdom="shared",version="v7.4.4"} 1
+	# HELP fortigate_vdom_session_count Number of sessions
+	# TYPE fortigate_vdom_session_count gauge
+	fortigate_vdom_session_count{site="zrh1",target_vdom="shared",vdom="root"} 7
+	`
+	if err := testutil.GatherAndCompare(r, strings.NewReader(em)); err != nil {
+		t.Fatalf("metric compare: err %v", err)
+	}
+	// The const label of the metric stays one
+	if d := ms[2].Desc().String(); !strings.Contains(d, `vdom="root"`) || strings.Contains(d, "variableLabels: {vdom") {
+		t.Errorf("desc = %s, want vdom as const label", d)
+	}
+
+	dm := &dto.Metric{}
+	if err := ms[0].Write(dm); err != nil {
+		t.Fatal(err)
+	}
+	if got := dm.GetCounter().GetCreatedTimestamp().AsTime(); !got.Equal(created) {
+		t.Errorf("created timestamp = %v, want %v", got, created)
+	}
+}
+
+func TestRelabelRules(t *testing.T) {
+	var rules []config.RelabelConfig
+	err := yaml.Unmarshal([]byte(`
+- source_labels: [__name__, mac]
+  regex: fortigate_wifi_client_info;aa:.*
+  action: drop
+- source_labels: [__name__]
+  regex: fortigate_wifi_.*
+  action: keep
+- regex: (mac|ip)
+  action: labeldrop
+- source_labels: [ssid]
+  regex: (.*)-guest
+  target_label: network
+`), &rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info := prometheus.NewDesc("fortigate_wifi_client_info", "Wifi client", []string{"mac", "ip", "ssid"}, nil)
+	other := prometheus.NewDesc("fortigate_version_info", "System version", []string{"version"}, nil)
+	ms := relabel([]prometheus.Metric{
+		prometheus.MustNewConstMetric(info, prometheus.GaugeValue, 1, "aa:bb", "10.0.0.1", "corp"),
+		prometheus.MustNewConstMetric(info, prometheus.GaugeValue, 1, "cc:dd", "10.0.0.2", "shop-guest"),
+		prometheus.MustNewConstMetric(info, prometheus.GaugeValue, 1, "ee:ff", "10.0.0.3", "shop-guest"),
+		prometheus.MustNewConstMetric(info, prometheus.GaugeValue, 1, "00:11", "10.0.0.4", "corp"),
+		prometheus.MustNewConstMetric(other, prometheus.GaugeValue, 1, "v7.4.4"),
+	}, rules, slog.Default())
+
+	r := prometheus.NewRegistry()
+	r.MustRegister(&ProbeCollector{metrics: ms})
+	// The client ee:ff is a duplicate of cc:dd once mac and ip are dropped
+	em := `
+	# HELP fortigate_wifi_client_info Wifi client
+	# TYPE fortigate_wifi_client_info gauge
+	fortigate_wifi_client_info{network="shop",ssid="shop-guest"} 1
+	fortigate_wifi_client_info{ssid="corp"} 1
+	`
+	if err := testutil.GatherAndCompare(r, strings.NewReader(em)); err != nil {
+		t.Fatalf("metric compare: err %v", err)
+	}
+}
+
+func TestRelabelConfigInvalid(t *testing.T) {
+	for _, c := range []string{
+		"- action: hashmod",
+		"- regex: '('\n  action: labeldrop",
+		"- action: drop",
+		"- regex: foo",
+	} {
+		var rules []config.RelabelConfig
+		if err := yaml.Unmarshal([]byte(c), &rules); err == nil {
+			t.Errorf("expected %q to be rejected", c)
+		}
+	}
+}
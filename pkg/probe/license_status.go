@@ -14,12 +14,35 @@
 package probe
 
 import (
-	"log"
+	"encoding/json"
+	"sort"
 
 	"github.com/prometheus-community/fortigate_exporter/pkg/http"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+// LicenseUpdate is a downloadable part of a FortiGuard service, e.g. its
+// signature database or scanning engine
+type LicenseUpdate struct {
+	Version    string  `json:"version"`
+	LastUpdate float64 `json:"last_update"`
+}
+
+// LicenseService is the license of a FortiCare or FortiGuard entitlement
+type LicenseService struct {
+	Type        string  `json:"type"`
+	Status      string  `json:"status"`
+	Entitlement string  `json:"entitlement"`
+	Expires     float64 `json:"expires"`
+	LicenseUpdate
+	Engine              *LicenseUpdate `json:"engine"`
+	ConfigurationScript *LicenseUpdate `json:"configuration_script"`
+	Support             map[string]struct {
+		Status  string  `json:"status"`
+		Expires float64 `json:"expires"`
+	} `json:"support"`
+}
+
 func probeLicenseStatus(c http.FortiHTTP, meta *TargetMetadata) ([]prometheus.Metric, bool) {
 	var (
 		vdomUsed = prometheus.NewDesc(
@@ -32,6 +55,26 @@ func probeLicenseStatus(c http.FortiHTTP, meta *TargetMetadata) ([]prometheus.Me
 			"The total amount of VDOM licenses available",
 			[]string{}, nil,
 		)
+		serviceInfo = prometheus.NewDesc(
+			"fortigate_license_status_info",
+			"License status of a FortiCare or FortiGuard service, or of a FortiCare support contract",
+			[]string{"service", "contract", "type", "status", "entitlement"}, nil,
+		)
+		serviceExpires = prometheus.NewDesc(
+			"fortigate_license_expiry_timestamp_seconds",
+			"Unix timestamp at which the license of the service or its support contract expires",
+			[]string{"service", "contract"}, nil,
+		)
+		serviceLastUpdate = prometheus.NewDesc(
+			"fortigate_license_last_update_timestamp_seconds",
+			"Unix timestamp of the last update of a service component",
+			[]string{"service", "component"}, nil,
+		)
+		serviceVersion = prometheus.NewDesc(
+			"fortigate_license_version_info",
+			"Version of a service component, e.g. the signature database or engine",
+			[]string{"service", "component", "version"}, nil,
+		)
 	)
 
 	type LicenseStatus struct {
@@ -44,18 +87,69 @@ func probeLicenseStatus(c http.FortiHTTP, meta *TargetMetadata) ([]prometheus.Me
 	}
 
 	type LicenseResponse struct {
-		Results LicenseStatus `json:"results"`
+		Results json.RawMessage `json:"results"`
 	}
 	var r LicenseResponse
 
 	if err := c.Get("api/v2/monitor/license/status/select", "", &r); err != nil {
-		log.Printf("Error: %v", err)
+		meta.Logger().Error("API request failed", "err", err)
+		return nil, false
+	}
+
+	var ls LicenseStatus
+	if err := json.Unmarshal(r.Results, &ls); err != nil {
+		meta.Logger().Error("Failed to decode license status", "err", err)
+		return nil, false
+	}
+	// The services differ between models and versions, decode all of them
+	var services map[string]json.RawMessage
+	if err := json.Unmarshal(r.Results, &services); err != nil {
+		meta.Logger().Error("Failed to decode license status", "err", err)
 		return nil, false
 	}
 
 	m := []prometheus.Metric{
-		prometheus.MustNewConstMetric(vdomUsed, prometheus.GaugeValue, float64(r.Results.VDOM.Used)),
-		prometheus.MustNewConstMetric(vdomMax, prometheus.GaugeValue, float64(r.Results.VDOM.Max)),
+		prometheus.MustNewConstMetric(vdomUsed, prometheus.GaugeValue, float64(ls.VDOM.Used)),
+		prometheus.MustNewConstMetric(vdomMax, prometheus.GaugeValue, float64(ls.VDOM.Max)),
+	}
+
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		var s LicenseService
+		if err := json.Unmarshal(services[name], &s); err != nil || s.Status == "" {
+			// Not a licensed service, e.g. the VDOM or FortiGuard connection status
+			continue
+		}
+		m = append(m, prometheus.MustNewConstMetric(serviceInfo, prometheus.GaugeValue, 1, name, "", s.Type, s.Status, s.Entitlement))
+		if s.Expires > 0 {
+			m = append(m, prometheus.MustNewConstMetric(serviceExpires, prometheus.GaugeValue, s.Expires, name, ""))
+		}
+		for component, u := range map[string]*LicenseUpdate{
+			"database":             &s.LicenseUpdate,
+			"engine":               s.Engine,
+			"configuration_script": s.ConfigurationScript,
+		} {
+			if u == nil || (u.Version == "" && u.LastUpdate == 0) {
+				continue
+			}
+			if u.LastUpdate > 0 {
+				m = append(m, prometheus.MustNewConstMetric(serviceLastUpdate, prometheus.GaugeValue, u.LastUpdate, name, component))
+			}
+			if u.Version != "" {
+				m = append(m, prometheus.MustNewConstMetric(serviceVersion, prometheus.GaugeValue, 1, name, component, u.Version))
+			}
+		}
+		// FortiCare has a contract per kind of support instead of a single expiry
+		for contract, sc := range s.Support {
+			m = append(m, prometheus.MustNewConstMetric(serviceInfo, prometheus.GaugeValue, 1, name, contract, s.Type, sc.Status, ""))
+			if sc.Expires > 0 {
+				m = append(m, prometheus.MustNewConstMetric(serviceExpires, prometheus.GaugeValue, sc.Expires, name, contract))
+			}
+		}
 	}
 
 	return m, true
@@ -30,6 +30,9 @@ func TestLicenseStatus(t *testing.T) {
 	}
 
 	em := `
+        # HELP fortigate_license_status_info License status of a FortiCare or FortiGuard service, or of a FortiCare support contract
+        # TYPE fortigate_license_status_info gauge
+        fortigate_license_status_info{contract="",entitlement="",service="sms",status="no_license",type="other"} 1
         # HELP fortigate_license_vdom_usage The amount of VDOM licenses currently used
         # TYPE fortigate_license_vdom_usage gauge
         fortigate_license_vdom_usage 114
@@ -41,3 +44,93 @@ func TestLicenseStatus(t *testing.T) {
 		t.Fatalf("metric compare: err %v", err)
 	}
 }
+
+func TestLicenseStatusEntitlements(t *testing.T) {
+	c := newFakeClient()
+	c.prepare("api/v2/monitor/license/status/select", "testdata/license-61f-full.jsonnet")
+	r := prometheus.NewPedanticRegistry()
+	if !testProbe(probeLicenseStatus, c, r) {
+		t.Errorf("probeLicenseStatus() returned non-success")
+	}
+
+	em := `
+        # HELP fortigate_license_expiry_timestamp_seconds Unix timestamp at which the license of the service or its support contract expires
+        # TYPE fortigate_license_expiry_timestamp_seconds gauge
+        fortigate_license_expiry_timestamp_seconds{contract="",service="antispam"} 1.5898464e+09
+        fortigate_license_expiry_timestamp_seconds{contract="",service="appctrl"} 1.6216416e+09
+        fortigate_license_expiry_timestamp_seconds{contract="",service="blacklisted_certificates"} 1.5898464e+09
+        fortigate_license_expiry_timestamp_seconds{contract="",service="device_os_id"} 1.6216416e+09
+        fortigate_license_expiry_timestamp_seconds{contract="enhanced",service="forticare"} 1.6216416e+09
+        fortigate_license_expiry_timestamp_seconds{contract="hardware",service="forticare"} 1.6216416e+09
+        fortigate_license_expiry_timestamp_seconds{contract="",service="web_filtering"} 1.5898464e+09
+        # HELP fortigate_license_last_update_timestamp_seconds Unix timestamp of the last update of a service component
+        # TYPE fortigate_license_last_update_timestamp_seconds gauge
+        fortigate_license_last_update_timestamp_seconds{component="configuration_script",service="ips"} 1.55982972e+09
+        fortigate_license_last_update_timestamp_seconds{component="database",service="antivirus"} 1.52329722e+09
+        fortigate_license_last_update_timestamp_seconds{component="database",service="appctrl"} 1.590107531e+09
+        fortigate_license_last_update_timestamp_seconds{component="database",service="blacklisted_certificates"} 9.783072e+08
+        fortigate_license_last_update_timestamp_seconds{component="database",service="botnet_domain"} 9.783072e+08
+        fortigate_license_last_update_timestamp_seconds{component="database",service="botnet_ip"} 1.33824546e+09
+        fortigate_license_last_update_timestamp_seconds{component="database",service="device_os_id"} 1.590171012e+09
+        fortigate_license_last_update_timestamp_seconds{component="database",service="industrial_db"} 1.448937e+09
+        fortigate_license_last_update_timestamp_seconds{component="database",service="internet_service_db"} 1.590437427e+09
+        fortigate_license_last_update_timestamp_seconds{component="database",service="ips"} 1.448937e+09
+        fortigate_license_last_update_timestamp_seconds{component="database",service="malicious_urls"} 1.590415883e+09
+        fortigate_license_last_update_timestamp_seconds{component="database",service="mobile_malware"} 9.783072e+08
+        fortigate_license_last_update_timestamp_seconds{component="database",service="security_rating"} 1.58663226e+09
+        fortigate_license_last_update_timestamp_seconds{component="engine",service="antivirus"} 1.5823359e+09
+        fortigate_license_last_update_timestamp_seconds{component="engine",service="ips"} 1.58886648e+09
+        # HELP fortigate_license_status_info License status of a FortiCare or FortiGuard service, or of a FortiCare support contract
+        # TYPE fortigate_license_status_info gauge
+        fortigate_license_status_info{contract="",entitlement="",service="forticare",status="registered",type="cloud_service_status"} 1
+        fortigate_license_status_info{contract="enhanced",entitlement="",service="forticare",status="licensed",type="cloud_service_status"} 1
+        fortigate_license_status_info{contract="hardware",entitlement="",service="forticare",status="licensed",type="cloud_service_status"} 1
+        fortigate_license_status_info{contract="",entitlement="",service="forticloud",status="cloud_logged_in",type="cloud_service_status"} 1
+        fortigate_license_status_info{contract="",entitlement="",service="forticloud_logging",status="free_license",type="live_cloud_service"} 1
+        fortigate_license_status_info{contract="",entitlement="",service="internet_service_db",status="licensed",type="downloaded_fds_object"} 1
+        fortigate_license_status_info{contract="",entitlement="",service="sms",status="no_license",type="other"} 1
+        fortigate_license_status_info{contract="",entitlement="AVDB",service="antivirus",status="no_license",type="downloaded_fds_object"} 1
+        fortigate_license_status_info{contract="",entitlement="AVDB",service="botnet_domain",status="no_license",type="downloaded_fds_object"} 1
+        fortigate_license_status_info{contract="",entitlement="AVDB",service="botnet_ip",status="no_license",type="downloaded_fds_object"} 1
+        fortigate_license_status_info{contract="",entitlement="AVDB",service="forticloud_sandbox",status="free_license",type="live_cloud_service"} 1
+        fortigate_license_status_info{contract="",entitlement="AVDB",service="mobile_malware",status="no_license",type="downloaded_fds_object"} 1
+        fortigate_license_status_info{contract="",entitlement="FAZC",service="fortianalyzer_cloud",status="no_license",type="live_cloud_service"} 1
+        fortigate_license_status_info{contract="",entitlement="FGSA",service="security_rating",status="no_license",type="downloaded_fds_object"} 1
+        fortigate_license_status_info{contract="",entitlement="FMGC",service="fortimanager_cloud",status="no_license",type="live_cloud_service"} 1
+        fortigate_license_status_info{contract="",entitlement="FMWR",service="appctrl",status="licensed",type="downloaded_fds_object"} 1
+        fortigate_license_status_info{contract="",entitlement="FMWR",service="device_os_id",status="licensed",type="downloaded_fds_object"} 1
+        fortigate_license_status_info{contract="",entitlement="FURL",service="blacklisted_certificates",status="expired",type="downloaded_fds_object"} 1
+        fortigate_license_status_info{contract="",entitlement="FURL",service="web_filtering",status="expired",type="live_fortiguard_service"} 1
+        fortigate_license_status_info{contract="",entitlement="ISSS",service="industrial_db",status="no_license",type="downloaded_fds_object"} 1
+        fortigate_license_status_info{contract="",entitlement="NIDS",service="ips",status="no_license",type="downloaded_fds_object"} 1
+        fortigate_license_status_info{contract="",entitlement="NIDS",service="malicious_urls",status="no_license",type="downloaded_fds_object"} 1
+        fortigate_license_status_info{contract="",entitlement="SPAM",service="antispam",status="expired",type="live_fortiguard_service"} 1
+        fortigate_license_status_info{contract="",entitlement="ZHVO",service="outbreak_prevention",status="no_license",type="live_fortiguard_service"} 1
+        # HELP fortigate_license_vdom_max The total amount of VDOM licenses available
+        # TYPE fortigate_license_vdom_max gauge
+        fortigate_license_vdom_max 10
+        # HELP fortigate_license_vdom_usage The amount of VDOM licenses currently used
+        # TYPE fortigate_license_vdom_usage gauge
+        fortigate_license_vdom_usage 4
+        # HELP fortigate_license_version_info Version of a service component, e.g. the signature database or engine
+        # TYPE fortigate_license_version_info gauge
+        fortigate_license_version_info{component="configuration_script",service="ips",version="1.00009"} 1
+        fortigate_license_version_info{component="database",service="antivirus",version="1.00000"} 1
+        fortigate_license_version_info{component="database",service="appctrl",version="15.00848"} 1
+        fortigate_license_version_info{component="database",service="blacklisted_certificates",version="0.00000"} 1
+        fortigate_license_version_info{component="database",service="botnet_domain",version="0.00000"} 1
+        fortigate_license_version_info{component="database",service="botnet_ip",version="1.00000"} 1
+        fortigate_license_version_info{component="database",service="device_os_id",version="1.00100"} 1
+        fortigate_license_version_info{component="database",service="industrial_db",version="6.00741"} 1
+        fortigate_license_version_info{component="database",service="internet_service_db",version="7.00715"} 1
+        fortigate_license_version_info{component="database",service="ips",version="6.00741"} 1
+        fortigate_license_version_info{component="database",service="malicious_urls",version="2.00654"} 1
+        fortigate_license_version_info{component="database",service="mobile_malware",version="0.00000"} 1
+        fortigate_license_version_info{component="database",service="security_rating",version="2.00036"} 1
+        fortigate_license_version_info{component="engine",service="antivirus",version="6.00144"} 1
+        fortigate_license_version_info{component="engine",service="ips",version="5.00209"} 1
+	`
+	if err := testutil.GatherAndCompare(r, strings.NewReader(em)); err != nil {
+		t.Fatalf("metric compare: err %v", err)
+	}
+}
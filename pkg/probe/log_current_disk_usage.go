@@ -14,8 +14,6 @@
 package probe
 
 import (
-	"log"
-
 	"github.com/prometheus-community/fortigate_exporter/pkg/http"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -46,7 +44,7 @@ func probeLogCurrentDiskUsage(c http.FortiHTTP, meta *TargetMetadata) ([]prometh
 
 	var res []Log
 	if err := c.Get("api/v2/monitor/log/current-disk-usage", "vdom=*", &res); err != nil {
-		log.Printf("Error: %v", err)
+		meta.Logger().Error("API request failed", "err", err)
 		return nil, false
 	}
 
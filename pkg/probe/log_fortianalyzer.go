@@ -14,8 +14,6 @@
 package probe
 
 import (
-	"log"
-
 	"github.com/prometheus-community/fortigate_exporter/pkg/http"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -47,7 +45,7 @@ func probeLogAnalyzer(c http.FortiHTTP, meta *TargetMetadata) ([]prometheus.Metr
 
 	var res []LogAna
 	if err := c.Get("api/v2/monitor/log/fortianalyzer", "vdom=*", &res); err != nil {
-		log.Printf("Error: %v", err)
+		meta.Logger().Error("API request failed", "err", err)
 		return nil, false
 	}
 
@@ -16,13 +16,20 @@ package probe
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"time"
 
 	"github.com/prometheus-community/fortigate_exporter/internal/config"
+	"github.com/prometheus-community/fortigate_exporter/internal/tracing"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/common/expfmt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func ProbeHandler(w http.ResponseWriter, r *http.Request) {
@@ -43,6 +50,11 @@ func ProbeHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Target parameter missing or empty", http.StatusBadRequest)
 		return
 	}
+	format, err := negotiateFormat(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	probeSuccessGauge := prometheus.NewGauge(prometheus.GaugeOpts{
 		Name: "probe_success",
 		Help: "Whether or not the probe succeeded",
@@ -51,7 +63,11 @@ func ProbeHandler(w http.ResponseWriter, r *http.Request) {
 		Name: "probe_duration_seconds",
 		Help: "How many seconds the probe took to complete",
 	})
-	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(savedConfig.ScrapeTimeout)*time.Second)
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	ctx, span := tracing.Tracer().Start(ctx, "probe", trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attribute.String("fortigate.target", target)))
+	defer span.End()
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(savedConfig.ScrapeTimeout)*time.Second)
 	defer cancel()
 	registry := prometheus.NewRegistry()
 	registry.MustRegister(probeSuccessGauge)
@@ -59,21 +75,45 @@ func ProbeHandler(w http.ResponseWriter, r *http.Request) {
 	start := time.Now()
 	pc := &ProbeCollector{}
 	registry.MustRegister(pc)
-	success, err := pc.Probe(ctx, paramMap, &http.Client{}, savedConfig)
+	success, err := pc.Probe(ctx, paramMap, savedConfig)
 	if err != nil {
-		log.Printf("Probe request rejected; error is: %v", err)
+		span.SetStatus(codes.Error, err.Error())
+		slog.Warn("Probe request rejected", "target", target, "err", err)
 		http.Error(w, fmt.Sprintf("probe: %v", err), http.StatusBadRequest)
 		return
 	}
-	duration := time.Since(start).Seconds()
+	elapsed := time.Since(start)
+	duration := elapsed.Seconds()
 	probeDurationGauge.Set(duration)
 	if success {
 		probeSuccessGauge.Set(1)
-		log.Printf("Probe of %q succeeded, took %.3f seconds", target, duration)
+		slog.Info("Probe succeeded", "target", target, "duration", elapsed)
 	} else {
 		// probeSuccessGauge default is 0
-		log.Printf("Probe of %q failed, took %.3f seconds", target, duration)
+		span.SetStatus(codes.Error, "probe failed")
+		slog.Warn("Probe failed", "target", target, "duration", elapsed)
+	}
+	switch format {
+	case formatJSON:
+		err = writeJSON(w, target, success, elapsed, pc)
+	case formatPrometheus, formatOpenMetrics:
+		if params.Get("format") != "" {
+			// The format parameter overrides the Accept header
+			r = r.Clone(r.Context())
+			r.Header.Set("Accept", string(expfmt.NewFormat(formatTypes[format])))
+		}
+		if format == formatOpenMetrics {
+			err = writeOpenMetrics(w, r, registry)
+			break
+		}
+		fallthrough
+	default:
+		promhttp.HandlerFor(registry, promhttp.HandlerOpts{
+			EnableOpenMetrics:                   true,
+			EnableOpenMetricsTextCreatedSamples: true,
+		}).ServeHTTP(w, r)
+	}
+	if err != nil {
+		slog.Error("Failed to write probe response", "target", target, "format", format, "err", err)
 	}
-	h := promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
-	h.ServeHTTP(w, r)
 }
@@ -0,0 +1,111 @@
+// Copyright 2025 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package probe
+
+import (
+	"sync"
+	"time"
+)
+
+// neighborKey identifies an ARP or IPv6 neighbor entry of a target
+type neighborKey struct {
+	VDOM      string
+	IPVersion string
+	IP        string
+}
+
+// neighborGroup is what the MAC changes are counted by
+type neighborGroup struct {
+	VDOM      string
+	Interface string
+	IPVersion string
+}
+
+type neighborEntry struct {
+	MAC       string
+	Interface string
+}
+
+// neighborRetention is how long the neighbor table of a target is kept after
+// its last scrape, so targets that are no longer probed do not stay forever
+const neighborRetention = time.Hour
+
+// neighborState keeps the neighbor tables of the last scrape of every target
+// to count the entries whose MAC address changed since then
+type neighborState struct {
+	mu      sync.Mutex
+	targets map[string]*neighborTargetState
+}
+
+type neighborTargetState struct {
+	entries map[neighborKey]neighborEntry
+	changes map[neighborGroup]float64
+	at      time.Time
+}
+
+var neighbors = &neighborState{targets: map[string]*neighborTargetState{}}
+
+// update replaces the neighbor table of target and returns the number of MAC
+// changes per group since the first scrape of target. Previous entries for
+// which keep returns true are carried over, e.g. if they could not be fetched.
+// Groups without entries are dropped and so are targets not updated within
+// neighborRetention.
+func (s *neighborState) update(target string, entries map[neighborKey]neighborEntry, keep func(neighborKey) bool, now time.Time) map[neighborGroup]float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for t, ts := range s.targets {
+		if now.Sub(ts.at) > neighborRetention {
+			delete(s.targets, t)
+		}
+	}
+	ts, ok := s.targets[target]
+	if !ok {
+		ts = &neighborTargetState{changes: map[neighborGroup]float64{}}
+		s.targets[target] = ts
+	}
+	for k, e := range entries {
+		g := neighborGroup{k.VDOM, e.Interface, k.IPVersion}
+		if _, found := ts.changes[g]; !found {
+			ts.changes[g] = 0
+		}
+		if prev, found := ts.entries[k]; found && prev.MAC != e.MAC {
+			ts.changes[g]++
+		}
+	}
+	if keep != nil {
+		for k, e := range ts.entries {
+			if _, found := entries[k]; !found && keep(k) {
+				entries[k] = e
+			}
+		}
+	}
+	ts.entries = entries
+	ts.at = now
+
+	groups := make(map[neighborGroup]bool, len(ts.changes))
+	for k, e := range entries {
+		groups[neighborGroup{k.VDOM, e.Interface, k.IPVersion}] = true
+	}
+	for g := range ts.changes {
+		if !groups[g] {
+			delete(ts.changes, g)
+		}
+	}
+
+	changes := make(map[neighborGroup]float64, len(ts.changes))
+	for g, v := range ts.changes {
+		changes[g] = v
+	}
+	return changes
+}
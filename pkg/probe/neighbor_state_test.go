@@ -0,0 +1,78 @@
+// Copyright 2025 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package probe
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNeighborStateKeep(t *testing.T) {
+	s := &neighborState{targets: map[string]*neighborTargetState{}}
+	v4 := neighborKey{"root", "ipv4", "192.168.1.10"}
+	v6 := neighborKey{"root", "ipv6", "2001:db8::10"}
+	g6 := neighborGroup{"root", "internal", "ipv6"}
+	now := time.Unix(1700000000, 0)
+
+	s.update("fgt", map[neighborKey]neighborEntry{
+		v4: {"00:09:0f:aa:00:01", "internal"},
+		v6: {"00:09:0f:aa:00:01", "internal"},
+	}, nil, now)
+	// The IPv6 table could not be fetched, its entries are kept for the next scrape
+	s.update("fgt", map[neighborKey]neighborEntry{
+		v4: {"00:09:0f:aa:00:01", "internal"},
+	}, func(k neighborKey) bool { return k.IPVersion == "ipv6" }, now)
+	changes := s.update("fgt", map[neighborKey]neighborEntry{
+		v4: {"00:09:0f:aa:00:01", "internal"},
+		v6: {"00:09:0f:aa:00:02", "internal"},
+	}, nil, now)
+	if changes[g6] != 1 {
+		t.Errorf("update() counted %v IPv6 MAC changes, want 1", changes[g6])
+	}
+
+	// Targets do not share state
+	changes = s.update("other", map[neighborKey]neighborEntry{
+		v6: {"00:09:0f:aa:00:03", "internal"},
+	}, nil, now)
+	if changes[g6] != 0 {
+		t.Errorf("update() counted %v IPv6 MAC changes for a new target, want 0", changes[g6])
+	}
+}
+
+func TestNeighborStateEviction(t *testing.T) {
+	s := &neighborState{targets: map[string]*neighborTargetState{}}
+	v4 := neighborKey{"root", "ipv4", "192.168.1.10"}
+	g4 := neighborGroup{"root", "internal", "ipv4"}
+	now := time.Unix(1700000000, 0)
+
+	s.update("fgt", map[neighborKey]neighborEntry{v4: {"00:09:0f:aa:00:01", "internal"}}, nil, now)
+	s.update("gone", map[neighborKey]neighborEntry{v4: {"00:09:0f:aa:00:01", "internal"}}, nil, now)
+
+	// The neighbor moved to another interface, its old group is dropped
+	changes := s.update("fgt", map[neighborKey]neighborEntry{v4: {"00:09:0f:aa:00:01", "wan1"}}, nil, now.Add(neighborRetention))
+	if _, ok := changes[g4]; ok {
+		t.Errorf("update() returned group %v without entries", g4)
+	}
+	if _, ok := s.targets["gone"]; !ok {
+		t.Errorf("target within retention was evicted")
+	}
+
+	s.update("fgt", map[neighborKey]neighborEntry{v4: {"00:09:0f:aa:00:01", "wan1"}}, nil, now.Add(neighborRetention+time.Second))
+	if _, ok := s.targets["gone"]; ok {
+		t.Errorf("target not updated within retention was kept")
+	}
+	if _, ok := s.targets["fgt"]; !ok {
+		t.Errorf("updated target was evicted")
+	}
+}
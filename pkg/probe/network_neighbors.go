@@ -0,0 +1,96 @@
+// Copyright 2025 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package probe
+
+import (
+	"github.com/prometheus-community/fortigate_exporter/internal/config"
+	"github.com/prometheus-community/fortigate_exporter/pkg/http"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type NeighborEntry struct {
+	IP        string `json:"ip"`
+	MAC       string `json:"mac"`
+	Interface string `json:"interface"`
+}
+
+type NeighborEntries struct {
+	Results []NeighborEntry `json:"results"`
+	VDOM    string          `json:"vdom"`
+}
+
+// incompleteMAC is reported for entries that are still being resolved
+const incompleteMAC = "00:00:00:00:00:00"
+
+func probeNetworkNeighbors(c http.FortiHTTP, meta *TargetMetadata) ([]prometheus.Metric, bool) {
+	savedConfig := config.GetConfig()
+
+	var (
+		entries = prometheus.NewDesc(
+			"fortigate_neighbor_entries",
+			"Number of entries in the ARP and IPv6 neighbor tables by interface",
+			[]string{"vdom", "interface", "ip_version"}, nil,
+		)
+		macChanges = prometheus.NewDesc(
+			"fortigate_neighbor_mac_changes_total",
+			"Number of neighbor entries seen with a different MAC address than in the previous scrape",
+			[]string{"vdom", "interface", "ip_version"}, nil,
+		)
+	)
+
+	var arp []NeighborEntries
+	if err := c.Get("api/v2/monitor/network/arp", "vdom=*", &arp); err != nil {
+		meta.Logger().Error("API request failed", "err", err)
+		return nil, false
+	}
+
+	// The ARP table is returned without the IPv6 neighbors if they fail
+	ok := true
+	var ndp []NeighborEntries
+	if err := c.Get("api/v2/monitor/network/ipv6-neighbor", "vdom=*", &ndp); err != nil {
+		meta.Logger().Error("API request failed", "err", err)
+		ok = false
+	}
+
+	counts := map[neighborGroup]float64{}
+	table := map[neighborKey]neighborEntry{}
+	for ipVersion, res := range map[string][]NeighborEntries{"ipv4": arp, "ipv6": ndp} {
+		for _, r := range res {
+			for _, e := range r.Results {
+				counts[neighborGroup{r.VDOM, e.Interface, ipVersion}]++
+				if e.MAC != "" && e.MAC != incompleteMAC {
+					table[neighborKey{r.VDOM, ipVersion, e.IP}] = neighborEntry{e.MAC, e.Interface}
+				}
+			}
+		}
+	}
+
+	m := []prometheus.Metric{}
+	for g, v := range counts {
+		m = append(m, prometheus.MustNewConstMetric(entries, prometheus.GaugeValue, v, g.VDOM, g.Interface, g.IPVersion))
+	}
+	// Flap detection is opt-in as it keeps the tables of all targets in memory
+	if savedConfig.NeighborFlaps {
+		// Keep the IPv6 entries of the previous scrape if they could not be
+		// fetched, else they would not be compared with the next scrape
+		var keep func(neighborKey) bool
+		if !ok {
+			keep = func(k neighborKey) bool { return k.IPVersion == "ipv6" }
+		}
+		for g, v := range neighbors.update(meta.target, table, keep, meta.Now()) {
+			m = append(m, prometheus.MustNewConstMetric(macChanges, prometheus.CounterValue, v, g.VDOM, g.Interface, g.IPVersion))
+		}
+	}
+	return m, ok
+}
@@ -0,0 +1,97 @@
+// Copyright 2025 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package probe
+
+import (
+	"errors"
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/prometheus-community/fortigate_exporter/internal/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestNetworkNeighbors(t *testing.T) {
+	c := newFakeClient()
+	c.prepare("api/v2/monitor/network/arp?vdom=*", "testdata/network-arp.jsonnet")
+	c.prepare("api/v2/monitor/network/ipv6-neighbor?vdom=*", "testdata/network-ipv6-neighbor.jsonnet")
+	r := prometheus.NewPedanticRegistry()
+	config.MustReInit()
+	if !testProbe(probeNetworkNeighbors, c, r) {
+		t.Errorf("probeNetworkNeighbors() returned non-success")
+	}
+
+	em := `
+	# HELP fortigate_neighbor_entries Number of entries in the ARP and IPv6 neighbor tables by interface
+	# TYPE fortigate_neighbor_entries gauge
+	fortigate_neighbor_entries{interface="internal",ip_version="ipv4",vdom="root"} 4
+	fortigate_neighbor_entries{interface="internal",ip_version="ipv6",vdom="root"} 2
+	fortigate_neighbor_entries{interface="vlan-knx",ip_version="ipv4",vdom="knx"} 2
+	fortigate_neighbor_entries{interface="wan1",ip_version="ipv4",vdom="root"} 1
+	fortigate_neighbor_entries{interface="wan1",ip_version="ipv6",vdom="root"} 1
+	`
+	if err := testutil.GatherAndCompare(r, strings.NewReader(em)); err != nil {
+		t.Fatalf("metric compare: err %v", err)
+	}
+}
+
+func TestNetworkNeighborsMACChanges(t *testing.T) {
+	flag.Set("neighbor-flap-detection", "true")
+	defer flag.Set("neighbor-flap-detection", "false")
+	config.MustReInit()
+	meta := &TargetMetadata{VersionMajor: 7, VersionMinor: 4, target: "https://flapping-fortigate"}
+
+	scrape := func(arp string) *prometheus.Registry {
+		c := newFakeClient()
+		c.prepare("api/v2/monitor/network/arp?vdom=*", arp)
+		c.prepare("api/v2/monitor/network/ipv6-neighbor?vdom=*", "testdata/network-ipv6-neighbor.jsonnet")
+		r := prometheus.NewPedanticRegistry()
+		if !testProbeWithMetadata(probeNetworkNeighbors, c, meta, r) {
+			t.Fatalf("probeNetworkNeighbors() returned non-success")
+		}
+		return r
+	}
+	scrape("testdata/network-arp.jsonnet")
+	r := scrape("testdata/network-arp_flapped.jsonnet")
+
+	// The entry that was incomplete in the first scrape is not a change
+	em := `
+	# HELP fortigate_neighbor_mac_changes_total Number of neighbor entries seen with a different MAC address than in the previous scrape
+	# TYPE fortigate_neighbor_mac_changes_total counter
+	fortigate_neighbor_mac_changes_total{interface="internal",ip_version="ipv4",vdom="root"} 2
+	fortigate_neighbor_mac_changes_total{interface="internal",ip_version="ipv6",vdom="root"} 0
+	fortigate_neighbor_mac_changes_total{interface="vlan-knx",ip_version="ipv4",vdom="knx"} 1
+	fortigate_neighbor_mac_changes_total{interface="wan1",ip_version="ipv4",vdom="root"} 0
+	fortigate_neighbor_mac_changes_total{interface="wan1",ip_version="ipv6",vdom="root"} 0
+	`
+	if err := testutil.GatherAndCompare(r, strings.NewReader(em), "fortigate_neighbor_mac_changes_total"); err != nil {
+		t.Fatalf("metric compare: err %v", err)
+	}
+}
+
+func TestNetworkNeighborsNoIPv6(t *testing.T) {
+	c := newFakeClient()
+	c.prepare("api/v2/monitor/network/arp?vdom=*", "testdata/network-arp.jsonnet")
+	c.prepareError("api/v2/monitor/network/ipv6-neighbor?vdom=*", errors.New("connection reset"))
+	config.MustReInit()
+	m, ok := probeNetworkNeighbors(c, &TargetMetadata{VersionMajor: 7, VersionMinor: 4})
+	if ok {
+		t.Errorf("probeNetworkNeighbors() returned success with failed IPv6 request")
+	}
+	if len(m) != 3 {
+		t.Errorf("probeNetworkNeighbors() returned %d metrics, want the 3 ARP table counts", len(m))
+	}
+}
@@ -33,24 +33,67 @@ package probe
 import (
 	"context"
 	"fmt"
-	"log"
-	"net/http"
+	"log/slog"
 	"net/url"
+	"path/filepath"
+	"runtime/debug"
 	"strings"
+	"time"
 
 	"github.com/prometheus-community/fortigate_exporter/internal/config"
+	"github.com/prometheus-community/fortigate_exporter/internal/tracing"
 	"github.com/prometheus-community/fortigate_exporter/internal/version"
 	fortiHTTP "github.com/prometheus-community/fortigate_exporter/pkg/http"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 )
 
 type ProbeCollector struct {
+	// Only overrides the include/exclude lists of the target when set
+	Only []string
+
+	metrics []prometheus.Metric
+	results []ProbeResult
+}
+
+// ProbeResult describes the outcome of a single probe run
+type ProbeResult struct {
+	Name    string
+	Success bool
+	// Stale is set when the probe failed and its last good values were returned
+	Stale    bool
+	Duration time.Duration
+	Metrics  int
+
 	metrics []prometheus.Metric
 }
 
 type TargetMetadata struct {
 	VersionMajor int
 	VersionMinor int
+	// target identifies the target for probes that keep state between scrapes
+	target string
+	logger *slog.Logger
+	// now is the clock of time dependent probes, time.Now if unset
+	now func() time.Time
+}
+
+// Logger returns the logger carrying the target and probe context
+func (m *TargetMetadata) Logger() *slog.Logger {
+	if m.logger == nil {
+		return slog.Default()
+	}
+	return m.logger
+}
+
+// Now returns the current time as seen by the probes
+func (m *TargetMetadata) Now() time.Time {
+	if m.now == nil {
+		return time.Now()
+	}
+	return m.now()
 }
 
 type probeFunc func(fortiHTTP.FortiHTTP, *TargetMetadata) ([]prometheus.Metric, bool)
@@ -60,7 +103,78 @@ type probeDetailedFunc struct {
 	function probeFunc
 }
 
-func (p *ProbeCollector) Probe(ctx context.Context, target map[string]string, hc *http.Client, savedConfig config.FortiExporterConfig) (bool, error) {
+var probeList = []probeDetailedFunc{
+	// Always keep probeSystemTime on top of the list to have the probe processed first.
+	// Therefore time returned is more accurate when integrated in Prometheus because
+	// timestamp for the metrics probe, in Prometheus, is obtained from the query time, not the reply time.
+	// This is especially important when running all the probes takes many seconds.
+	{"System/Time/Clock", probeSystemTime},
+	{"BGP/NeighborPaths/IPv4", probeBGPNeighborPathsIPv4},
+	{"BGP/NeighborPaths/IPv6", probeBGPNeighborPathsIPv6},
+	{"BGP/Neighbors/IPv4", probeBGPNeighborsIPv4},
+	{"BGP/Neighbors/IPv6", probeBGPNeighborsIPv6},
+	{"Firewall/LoadBalance", probeFirewallLoadBalance},
+	{"Firewall/Policies", probeFirewallPolicies},
+	{"Firewall/IpPool", probeFirewallIpPool},
+	{"License/Status", probeLicenseStatus},
+	{"Log/Fortianalyzer/Status", probeLogAnalyzer},
+	{"Log/Fortianalyzer/Queue", probeLogAnalyzerQueue},
+	{"Log/DiskUsage", probeLogCurrentDiskUsage},
+	{"Network/Neighbors", probeNetworkNeighbors},
+	{"System/AdminSessions", probeSystemAdminSessions},
+	{"System/AvailableCertificates", probeSystemAvailableCertificates},
+	{"System/ConfigRevision", probeSystemConfigRevision},
+	{"System/Connectivity", probeSystemConnectivity},
+	{"System/DHCP", probeSystemDHCP},
+	{"System/Firmware", probeSystemFirmware},
+	{"System/Fortimanager/Status", probeSystemFortimanagerStatus},
+	{"System/HAStatistics", probeSystemHAStatistics},
+	{"System/Interface", probeSystemInterface},
+	{"System/Interface/Transceivers", probeSystemInterfaceTransceivers},
+	{"System/LinkMonitor", probeSystemLinkMonitor},
+	{"System/Resource/Usage", probeSystemResourceUsage},
+	{"System/SDNConnector", probeSystemSDNConnector},
+	{"System/SensorInfo", probeSystemSensorInfo},
+	{"System/Status", probeSystemStatus},
+	{"System/VDOMResources", probeSystemVDOMResources},
+	{"System/HAChecksum", probeSystemHAChecksum},
+	{"User/Fsso", probeUserFsso},
+	{"VPN/IPSec", probeVPNIPSec},
+	{"VPN/Ssl/Connections", probeVPNSsl},
+	{"VPN/Ssl/Stats", probeVPNSslStats},
+	{"VirtualWAN/HealthCheck", probeVirtualWANHealthCheck},
+	{"WebUI/State", probeWebUIState},
+	{"Wifi/APStatus", probeWifiAPStatus},
+	{"Wifi/Clients", probeWifiClients},
+	{"Wifi/ManagedAP", probeWifiManagedAP},
+	{"Switch/ManagedSwitch", probeManagedSwitch},
+	{"OSPF/Neighbors", probeOSPFNeighbors},
+}
+
+var probePanics = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "fortigate_exporter_probe_panics_total",
+	Help: "Number of times a probe panicked, this is always a bug in the exporter",
+}, []string{"probe"})
+
+var probeSeriesLimitHits = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "fortigate_exporter_probe_series_limit_exceeded_total",
+	Help: "Number of times a probe returned more series than its series limit",
+}, []string{"probe"})
+
+// runProbe runs p and turns a panic into a failed probe so that a single
+// unexpected API response cannot take down the whole scrape.
+func runProbe(p probeDetailedFunc, c fortiHTTP.FortiHTTP, meta *TargetMetadata) (m []prometheus.Metric, ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			probePanics.WithLabelValues(p.name).Inc()
+			meta.Logger().Error("Probe panicked", "panic", r, "stack", string(debug.Stack()))
+			m, ok = nil, false
+		}
+	}()
+	return p.function(c, meta)
+}
+
+func (p *ProbeCollector) Probe(ctx context.Context, target map[string]string, savedConfig config.FortiExporterConfig) (bool, error) {
 	tgt, err := url.Parse(target["target"])
 	if err != nil {
 		return false, fmt.Errorf("url.Parse failed: %v", err)
@@ -77,16 +191,31 @@ func (p *ProbeCollector) Probe(ctx context.Context, target map[string]string, hc
 	}
 
 	if target["token"] != "" && savedConfig.AuthKeys[config.Target(target["target"])].Token == "" {
-		// Add the target and its apikey to the savedConfig and use, if exists, a target entry as a template for include/exclude
-		// This will only happened the "first" time
-		savedConfig.AuthKeys[config.Target(target["target"])] = config.TargetAuth{Token: config.Token(target["token"]),
-			Probes: savedConfig.AuthKeys[config.Target(target["profile"])].Probes}
+		// Add the target and its apikey to this request's copy of the savedConfig and use, if exists, a target entry
+		// as a template for include/exclude
+		auth := savedConfig.AuthKeys[config.Target(target["profile"])]
+		auth.Token = config.Token(target["token"])
+		savedConfig.AuthKeys = savedConfig.AuthKeys.With(config.Target(target["target"]), auth)
+	}
+
+	hc, err := fortiHTTP.TargetClient(u, savedConfig)
+	if err != nil {
+		return false, err
 	}
 
 	c, err := fortiHTTP.NewFortiClient(ctx, u, hc, savedConfig)
 	if err != nil {
 		return false, err
 	}
+	logger := slog.Default().With("target", u.String())
+	c = fortiHTTP.WithLogger(c, logger)
+
+	if savedConfig.RecordDir != "" {
+		dir := filepath.Join(savedConfig.RecordDir, strings.ReplaceAll(u.Host, ":", "_"))
+		if c, err = fortiHTTP.NewRecordingClient(c, dir, savedConfig.RecordRedact); err != nil {
+			return false, err
+		}
+	}
 
 	type systemStatus struct {
 		Status  string
@@ -98,71 +227,49 @@ func (p *ProbeCollector) Probe(ctx context.Context, target map[string]string, hc
 	// The "system status" group has access group "any" so it is a good source
 	// to test the authentication as well as fetching the OS version.
 	if err := c.Get("api/v2/monitor/system/status", "", &st); err != nil {
-		log.Printf("Error: API connectivity test failed, %v", err)
+		logger.Error("API connectivity test failed", "err", err)
 		return false, nil
 	}
 
 	if st.Status != "success" {
-		log.Printf("Error: API connectivity test returned status: %s", st.Status)
+		logger.Error("API connectivity test returned unexpected status", "status", st.Status)
 		return false, nil
 	}
 
 	major, minor, ok := version.ParseVersion(st.Version)
 	if !ok {
-		log.Printf("Error: Failed to parse OS version: %q", st.Version)
+		logger.Error("Failed to parse OS version", "version", st.Version)
 		return false, nil
 	}
 
 	meta := &TargetMetadata{
 		VersionMajor: major,
 		VersionMinor: minor,
+		target:       u.String(),
 	}
 
-	includedProbes := savedConfig.AuthKeys[config.Target(u.String())].Probes.Include
-	excludedProbes := savedConfig.AuthKeys[config.Target(u.String())].Probes.Exclude
+	auth := savedConfig.AuthKeys[config.Target(u.String())]
+	includedProbes := auth.Probes.Include
+	excludedProbes := auth.Probes.Exclude
+	if len(p.Only) != 0 {
+		includedProbes, excludedProbes = p.Only, nil
+	}
 
 	// TODO: Make parallel
+	mProbeSuccess := prometheus.NewDesc(
+		"fortigate_exporter_probe_success",
+		"Whether or not the individual probe succeeded",
+		[]string{"probe"}, nil,
+	)
+
+	mProbeStale := prometheus.NewDesc(
+		"fortigate_exporter_probe_stale",
+		"Whether the individual probe failed and its last good values were returned instead",
+		[]string{"probe"}, nil,
+	)
+
 	success := true
-	for _, aProbe := range []probeDetailedFunc{
-		// Always keep probeSystemTime on top of the list to have the probe processed first.
-		// Therefore time returned is more accurate when integrated in Prometheus because
-		// timestamp for the metrics probe, in Prometheus, is obtained from the query time, not the reply time.
-		// This is especially important when running all the probes takes many seconds.
-		{"System/Time/Clock", probeSystemTime},
-		{"BGP/NeighborPaths/IPv4", probeBGPNeighborPathsIPv4},
-		{"BGP/NeighborPaths/IPv6", probeBGPNeighborPathsIPv6},
-		{"BGP/Neighbors/IPv4", probeBGPNeighborsIPv4},
-		{"BGP/Neighbors/IPv6", probeBGPNeighborsIPv6},
-		{"Firewall/LoadBalance", probeFirewallLoadBalance},
-		{"Firewall/Policies", probeFirewallPolicies},
-		{"Firewall/IpPool", probeFirewallIpPool},
-		{"License/Status", probeLicenseStatus},
-		{"Log/Fortianalyzer/Status", probeLogAnalyzer},
-		{"Log/Fortianalyzer/Queue", probeLogAnalyzerQueue},
-		{"Log/DiskUsage", probeLogCurrentDiskUsage},
-		{"System/AvailableCertificates", probeSystemAvailableCertificates},
-		{"System/Fortimanager/Status", probeSystemFortimanagerStatus},
-		{"System/HAStatistics", probeSystemHAStatistics},
-		{"System/Interface", probeSystemInterface},
-		{"System/LinkMonitor", probeSystemLinkMonitor},
-		{"System/Resource/Usage", probeSystemResourceUsage},
-		{"System/SDNConnector", probeSystemSDNConnector},
-		{"System/SensorInfo", probeSystemSensorInfo},
-		{"System/Status", probeSystemStatus},
-		{"System/VDOMResources", probeSystemVDOMResources},
-		{"System/HAChecksum", probeSystemHAChecksum},
-		{"User/Fsso", probeUserFsso},
-		{"VPN/IPSec", probeVPNIPSec},
-		{"VPN/Ssl/Connections", probeVPNSsl},
-		{"VPN/Ssl/Stats", probeVPNSslStats},
-		{"VirtualWAN/HealthCheck", probeVirtualWANHealthCheck},
-		{"WebUI/State", probeWebUIState},
-		{"Wifi/APStatus", probeWifiAPStatus},
-		{"Wifi/Clients", probeWifiClients},
-		{"Wifi/ManagedAP", probeWifiManagedAP},
-		{"Switch/ManagedSwitch", probeManagedSwitch},
-		{"OSPF/Neighbors", probeOSPFNeighbors},
-	} {
+	for _, aProbe := range probeList {
 		wanted := false
 
 		if len(includedProbes) == 0 {
@@ -189,16 +296,67 @@ func (p *ProbeCollector) Probe(ctx context.Context, target map[string]string, hc
 			continue
 		}
 
-		m, ok := aProbe.function(c, meta)
+		plog := logger.With("probe", aProbe.name)
+		limit := auth.SeriesLimit(aProbe.name, savedConfig.DefaultSeriesLimit(aProbe.name))
+		pmeta := *meta
+		pmeta.logger = plog
+		pctx, span := tracing.Tracer().Start(ctx, aProbe.name)
+		pc := fortiHTTP.WithContext(fortiHTTP.WithLogger(c, plog), pctx)
+		start := time.Now()
+		m, ok := runProbe(aProbe, pc, &pmeta)
+		duration := time.Since(start)
+		m = withTargetLabels(m, auth.Labels, auth.LabelPrefix, plog)
+		m = relabel(m, auth.MetricRelabelConfigs, plog)
+		if limit > 0 && len(m) > limit {
+			plog.Error("Probe returned more series than allowed, dropping all of them", "series", len(m), "limit", limit)
+			probeSeriesLimitHits.WithLabelValues(aProbe.name).Inc()
+			m, ok = nil, false
+		}
+		stale := false
+		if retention := auth.StaleRetentionOf(aProbe.name); retention > 0 {
+			if ok {
+				lastGood.store(u.String(), aProbe.name, m, retention, start)
+			} else if sm, age, found := lastGood.load(u.String(), aProbe.name, retention, start); found {
+				if merged := mergeStale(m, sm); len(merged) > len(m) {
+					plog.Warn("Probe failed, returning last good values of the missing series", "age", age, "series", len(merged)-len(m))
+					m, stale = merged, true
+				}
+			}
+		}
+		p.results = append(p.results, ProbeResult{Name: aProbe.name, Success: ok, Stale: stale, Duration: duration, Metrics: len(m), metrics: m})
+		probeSuccess := 1.0
 		if !ok {
 			success = false
+			probeSuccess = 0.0
+			span.SetStatus(codes.Error, "probe failed")
+			plog.Warn("Probe failed", "duration", duration)
+		} else {
+			plog.Debug("Probe finished", "duration", duration, "metrics", len(m))
 		}
+		span.SetAttributes(attribute.Int("fortigate.metrics", len(m)))
+		span.End()
 		p.metrics = append(p.metrics, m...)
+		status := []prometheus.Metric{
+			prometheus.MustNewConstMetric(mProbeSuccess, prometheus.GaugeValue, probeSuccess, aProbe.name),
+		}
+		if auth.StaleRetentionOf(aProbe.name) > 0 {
+			probeStale := 0.0
+			if stale {
+				probeStale = 1.0
+			}
+			status = append(status, prometheus.MustNewConstMetric(mProbeStale, prometheus.GaugeValue, probeStale, aProbe.name))
+		}
+		p.metrics = append(p.metrics, withTargetLabels(status, auth.Labels, auth.LabelPrefix, plog)...)
 	}
 
 	return success, nil
 }
 
+// Results returns the outcome of every probe that was run, in order
+func (p *ProbeCollector) Results() []ProbeResult {
+	return p.results
+}
+
 func (p *ProbeCollector) Collect(c chan<- prometheus.Metric) {
 	// Collect result of new probe functions
 	for _, m := range p.metrics {
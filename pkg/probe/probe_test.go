@@ -41,12 +41,14 @@ import (
 )
 
 type preparedResp struct {
-	d []byte
-	q url.Values
+	d   []byte
+	q   url.Values
+	err error
 }
 
 type fakeClient struct {
-	data map[string][]preparedResp
+	data     map[string][]preparedResp
+	fallback []byte
 }
 
 func (c *fakeClient) prepare(path string, jfile string) {
@@ -65,8 +67,28 @@ func (c *fakeClient) prepare(path string, jfile string) {
 	})
 }
 
+// prepareError makes requests for path fail with err
+func (c *fakeClient) prepareError(path string, err error) {
+	u, perr := url.Parse(path)
+	if perr != nil {
+		panic(perr)
+	}
+	c.data[u.Path] = append(c.data[u.Path], preparedResp{
+		q:   u.Query(),
+		err: err,
+	})
+}
+
+// prepareFallback makes c answer every request without a prepared response with d
+func (c *fakeClient) prepareFallback(d []byte) {
+	c.fallback = d
+}
+
 func (c *fakeClient) Get(path string, query string, obj interface{}) error {
 	rs, ok := c.data[path]
+	if !ok && c.fallback != nil {
+		return json.Unmarshal(c.fallback, obj)
+	}
 	if !ok {
 		log.Fatalf("Tried to get unprepared URL %q", path)
 	}
@@ -81,6 +103,9 @@ alt:
 				continue alt
 			}
 		}
+		if r.err != nil {
+			return r.err
+		}
 		return json.Unmarshal(r.d, obj)
 	}
 	log.Fatalf("No prepared response matched URL %q, query %q", path, query)
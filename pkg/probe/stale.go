@@ -0,0 +1,104 @@
+// Copyright 2025 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package probe
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// staleCache keeps the last good metrics of the probes that have a stale
+// retention configured, so they can be returned when the probe fails. Entries
+// past their retention are removed when another entry is stored, so targets
+// that are no longer probed, e.g. ones given with token=, do not stay forever.
+type staleCache struct {
+	mu      sync.Mutex
+	entries map[string]staleEntry
+}
+
+type staleEntry struct {
+	metrics   []prometheus.Metric
+	at        time.Time
+	retention time.Duration
+}
+
+var lastGood = &staleCache{entries: map[string]staleEntry{}}
+
+func staleKey(target, probe string) string {
+	return target + "\x00" + probe
+}
+
+func (c *staleCache) store(target, probe string, ms []prometheus.Metric, retention time.Duration, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, e := range c.entries {
+		if now.Sub(e.at) > e.retention {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[staleKey(target, probe)] = staleEntry{metrics: ms, at: now, retention: retention}
+}
+
+// load returns the metrics stored for probe if they are not older than retention
+func (c *staleCache) load(target, probe string, retention time.Duration, now time.Time) ([]prometheus.Metric, time.Duration, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := staleKey(target, probe)
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, 0, false
+	}
+	age := now.Sub(e.at)
+	if age > retention {
+		delete(c.entries, key)
+		return nil, 0, false
+	}
+	return e.metrics, age, true
+}
+
+// mergeStale returns fresh plus the series of old that are missing in it, so
+// the partial results of a failing probe are not replaced by older values
+func mergeStale(fresh, old []prometheus.Metric) []prometheus.Metric {
+	have := make(map[string]bool, len(fresh))
+	for _, m := range fresh {
+		if k, ok := seriesKey(m); ok {
+			have[k] = true
+		}
+	}
+	merged := fresh
+	for _, m := range old {
+		if k, ok := seriesKey(m); ok && !have[k] {
+			merged = append(merged, m)
+		}
+	}
+	return merged
+}
+
+// seriesKey identifies the series of a metric by its descriptor and label values
+func seriesKey(m prometheus.Metric) (string, bool) {
+	dm := &dto.Metric{}
+	if err := m.Write(dm); err != nil {
+		return "", false
+	}
+	var b strings.Builder
+	b.WriteString(m.Desc().String())
+	for _, l := range dm.GetLabel() {
+		b.WriteString("\xff" + l.GetName() + "\xff" + l.GetValue())
+	}
+	return b.String(), true
+}
@@ -0,0 +1,132 @@
+// Copyright 2025 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package probe
+
+import (
+	nethttp "net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus-community/fortigate_exporter/internal/exportertest"
+	"github.com/prometheus-community/fortigate_exporter/internal/mock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestStaleCache(t *testing.T) {
+	c := &staleCache{entries: map[string]staleEntry{}}
+	now := time.Now()
+	c.store("https://fw", "System/Status", nil, time.Minute, now)
+
+	if _, age, ok := c.load("https://fw", "System/Status", time.Minute, now.Add(30*time.Second)); !ok || age != 30*time.Second {
+		t.Errorf("load() = %v, %v, want entry of age 30s", age, ok)
+	}
+	if _, _, ok := c.load("https://other", "System/Status", time.Minute, now); ok {
+		t.Errorf("load() returned an entry of another target")
+	}
+	if _, _, ok := c.load("https://fw", "System/Status", time.Minute, now.Add(2*time.Minute)); ok {
+		t.Errorf("load() returned an expired entry")
+	}
+	if len(c.entries) != 0 {
+		t.Errorf("expired entry was not removed")
+	}
+}
+
+func TestStaleCacheEviction(t *testing.T) {
+	c := &staleCache{entries: map[string]staleEntry{}}
+	now := time.Now()
+	c.store("https://fw?token=1", "System/Status", nil, time.Minute, now)
+	c.store("https://fw", "Firewall/Policies", nil, 10*time.Minute, now)
+
+	c.store("https://fw", "System/Status", nil, time.Minute, now.Add(2*time.Minute))
+	if _, ok := c.entries[staleKey("https://fw?token=1", "System/Status")]; ok {
+		t.Errorf("entry past its retention was not evicted")
+	}
+	if _, ok := c.entries[staleKey("https://fw", "Firewall/Policies")]; !ok {
+		t.Errorf("entry within its retention was evicted")
+	}
+}
+
+func TestMergeStale(t *testing.T) {
+	hits := prometheus.NewDesc("fortigate_policy_hit_count_total", "Number of times a policy has been hit", []string{"vdom", "protocol"}, nil)
+	fresh := []prometheus.Metric{
+		prometheus.MustNewConstMetric(hits, prometheus.CounterValue, 10, "root", "ipv4"),
+	}
+	old := []prometheus.Metric{
+		prometheus.MustNewConstMetric(hits, prometheus.CounterValue, 5, "root", "ipv4"),
+		prometheus.MustNewConstMetric(hits, prometheus.CounterValue, 3, "root", "ipv6"),
+	}
+
+	r := prometheus.NewPedanticRegistry()
+	r.MustRegister(&ProbeCollector{metrics: mergeStale(fresh, old)})
+	em := `
+	# HELP fortigate_policy_hit_count_total Number of times a policy has been hit
+	# TYPE fortigate_policy_hit_count_total counter
+	fortigate_policy_hit_count_total{protocol="ipv4",vdom="root"} 10
+	fortigate_policy_hit_count_total{protocol="ipv6",vdom="root"} 3
+	`
+	if err := testutil.GatherAndCompare(r, strings.NewReader(em)); err != nil {
+		t.Fatalf("metric compare: err %v", err)
+	}
+}
+
+func TestProbeHandlerStale(t *testing.T) {
+	var failing atomic.Bool
+	h := &mock.Handler{Fixtures: exportertest.Fixtures(t, "7.4"), Options: mock.Options{Token: "golden-token"}}
+	srv := httptest.NewTLSServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		if failing.Load() && strings.HasSuffix(r.URL.Path, "/firewall/policy/select") {
+			nethttp.Error(w, "internal error", nethttp.StatusInternalServerError)
+			return
+		}
+		h.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	configureGolden(t, srv, goldenCase{authEntry: goldenSmallProbes + `
+  stale_retention:
+    Firewall/Policies: 5m`})
+
+	scrape := func() string {
+		req := httptest.NewRequest("GET", "/probe?target="+url.QueryEscape(srv.URL), nil)
+		w := httptest.NewRecorder()
+		ProbeHandler(w, req)
+		return w.Body.String()
+	}
+
+	got := scrape()
+	for _, l := range []string{
+		`fortigate_exporter_probe_stale{probe="Firewall/Policies"} 0`,
+		`probe_success 1`,
+	} {
+		if !strings.Contains(got, l) {
+			t.Fatalf("first scrape is missing %q:\n%s", l, got)
+		}
+	}
+
+	failing.Store(true)
+	got = scrape()
+	for _, l := range []string{
+		`fortigate_exporter_probe_stale{probe="Firewall/Policies"} 1`,
+		`fortigate_exporter_probe_success{probe="Firewall/Policies"} 0`,
+		`fortigate_policy_hit_count_total{id="1",name="",protocol="ipv4",uuid="078f184c-9e9d-51ea-9fbb-66c20957b9c0",vdom="FG-traffic"}`,
+		`probe_success 0`,
+	} {
+		if !strings.Contains(got, l) {
+			t.Errorf("scrape of failing probe is missing %q", l)
+		}
+	}
+}
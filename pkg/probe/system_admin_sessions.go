@@ -0,0 +1,139 @@
+// Copyright 2025 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package probe
+
+import (
+	"github.com/prometheus-community/fortigate_exporter/internal/config"
+	"github.com/prometheus-community/fortigate_exporter/pkg/http"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type AdminSession struct {
+	Admin   string  `json:"admin"`
+	VDOM    string  `json:"vdom"`
+	Profile string  `json:"profile"`
+	SrcAddr string  `json:"srcaddr"`
+	Method  string  `json:"method"`
+	Time    float64 `json:"time"`
+}
+
+type AdminSessions struct {
+	Results []AdminSession `json:"results"`
+}
+
+type BannedIP struct {
+	IPAddress string  `json:"ip_address"`
+	Expires   float64 `json:"expires"`
+	Source    string  `json:"source"`
+}
+
+type BannedIPs struct {
+	Results []BannedIP `json:"results"`
+}
+
+func probeSystemAdminSessions(c http.FortiHTTP, meta *TargetMetadata) ([]prometheus.Metric, bool) {
+	savedConfig := config.GetConfig()
+	MaxAdminSessions := savedConfig.MaxAdmins
+	MaxBannedIPs := savedConfig.MaxBannedIPs
+
+	var (
+		sessions = prometheus.NewDesc(
+			"fortigate_admin_sessions",
+			"Number of active administrator sessions by profile and login method",
+			[]string{"profile", "method"}, nil,
+		)
+		userSessions = prometheus.NewDesc(
+			"fortigate_admin_user_sessions",
+			"Number of active sessions of an administrator by source address",
+			[]string{"admin", "profile", "method", "srcaddr"}, nil,
+		)
+		banned = prometheus.NewDesc(
+			"fortigate_banned_ips",
+			"Number of source IPs currently banned, by the feature that banned them",
+			[]string{"source"}, nil,
+		)
+		bannedExpiry = prometheus.NewDesc(
+			"fortigate_banned_ip_expiry_timestamp_seconds",
+			"Unix timestamp at which the ban of a source IP ends, not exported for bans that do not expire",
+			[]string{"ip", "source"}, nil,
+		)
+	)
+
+	var res AdminSessions
+	if err := c.Get("api/v2/monitor/system/current-admins", "", &res); err != nil {
+		meta.Logger().Error("API request failed", "err", err)
+		return nil, false
+	}
+
+	type sessionKey struct {
+		Profile string
+		Method  string
+	}
+	type userKey struct {
+		Admin   string
+		Profile string
+		Method  string
+		SrcAddr string
+	}
+	counts := map[sessionKey]float64{}
+	userCounts := map[userKey]float64{}
+	for _, s := range res.Results {
+		counts[sessionKey{s.Profile, s.Method}]++
+		userCounts[userKey{s.Admin, s.Profile, s.Method, s.SrcAddr}]++
+	}
+
+	m := []prometheus.Metric{}
+	for k, v := range counts {
+		m = append(m, prometheus.MustNewConstMetric(sessions, prometheus.GaugeValue, v, k.Profile, k.Method))
+	}
+	// Per administrator metrics are opt-in as every source address is a series
+	if MaxAdminSessions != 0 {
+		if len(res.Results) > MaxAdminSessions {
+			meta.Logger().Error("Received more admin sessions than maximum allowed, ignoring metric", "sessions", len(res.Results), "max", MaxAdminSessions)
+		} else {
+			for k, v := range userCounts {
+				m = append(m, prometheus.MustNewConstMetric(userSessions, prometheus.GaugeValue, v, k.Admin, k.Profile, k.Method, k.SrcAddr))
+			}
+		}
+	}
+
+	// The banned IPs need another permission, return the sessions without them
+	var br BannedIPs
+	if err := c.Get("api/v2/monitor/user/banned/select", "", &br); err != nil {
+		meta.Logger().Error("API request failed", "err", err)
+		return m, false
+	}
+	bannedCounts := map[string]float64{}
+	for _, b := range br.Results {
+		bannedCounts[b.Source]++
+	}
+	for s, v := range bannedCounts {
+		m = append(m, prometheus.MustNewConstMetric(banned, prometheus.GaugeValue, v, s))
+	}
+	if MaxBannedIPs != 0 {
+		if len(br.Results) > MaxBannedIPs {
+			meta.Logger().Error("Received more banned IPs than maximum allowed, ignoring metric", "ips", len(br.Results), "max", MaxBannedIPs)
+		} else {
+			for _, b := range br.Results {
+				// An expiry of 0 is a ban until an administrator lifts it
+				if b.Expires == 0 {
+					continue
+				}
+				m = append(m, prometheus.MustNewConstMetric(bannedExpiry, prometheus.GaugeValue, b.Expires, b.IPAddress, b.Source))
+			}
+		}
+	}
+
+	return m, true
+}
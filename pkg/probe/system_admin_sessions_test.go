@@ -0,0 +1,118 @@
+// Copyright 2025 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package probe
+
+import (
+	"errors"
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/prometheus-community/fortigate_exporter/internal/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestSystemAdminSessions(t *testing.T) {
+	c := newFakeClient()
+	c.prepare("api/v2/monitor/system/current-admins", "testdata/current-admins.jsonnet")
+	c.prepare("api/v2/monitor/user/banned/select", "testdata/user-banned.jsonnet")
+	r := prometheus.NewPedanticRegistry()
+	config.MustReInit()
+	if !testProbe(probeSystemAdminSessions, c, r) {
+		t.Errorf("probeSystemAdminSessions() returned non-success")
+	}
+
+	em := `
+	# HELP fortigate_admin_sessions Number of active administrator sessions by profile and login method
+	# TYPE fortigate_admin_sessions gauge
+	fortigate_admin_sessions{method="https",profile="prof_admin"} 2
+	fortigate_admin_sessions{method="https",profile="super_admin"} 1
+	fortigate_admin_sessions{method="ssh",profile="super_admin"} 2
+	# HELP fortigate_banned_ips Number of source IPs currently banned, by the feature that banned them
+	# TYPE fortigate_banned_ips gauge
+	fortigate_banned_ips{source="dos"} 1
+	fortigate_banned_ips{source="ips"} 2
+	`
+	if err := testutil.GatherAndCompare(r, strings.NewReader(em)); err != nil {
+		t.Fatalf("metric compare: err %v", err)
+	}
+}
+
+func TestSystemAdminSessionsPerAdmin(t *testing.T) {
+	c := newFakeClient()
+	c.prepare("api/v2/monitor/system/current-admins", "testdata/current-admins.jsonnet")
+	c.prepare("api/v2/monitor/user/banned/select", "testdata/user-banned.jsonnet")
+	r := prometheus.NewPedanticRegistry()
+	flag.Set("max-admin-sessions", "10")
+	defer flag.Set("max-admin-sessions", "0")
+	flag.Set("max-banned-ips", "10")
+	defer flag.Set("max-banned-ips", "0")
+	config.MustReInit()
+	if !testProbe(probeSystemAdminSessions, c, r) {
+		t.Errorf("probeSystemAdminSessions() returned non-success")
+	}
+
+	em := `
+	# HELP fortigate_admin_user_sessions Number of active sessions of an administrator by source address
+	# TYPE fortigate_admin_user_sessions gauge
+	fortigate_admin_user_sessions{admin="admin",method="https",profile="super_admin",srcaddr="10.20.0.5"} 1
+	fortigate_admin_user_sessions{admin="admin",method="ssh",profile="super_admin",srcaddr="10.20.0.5"} 1
+	fortigate_admin_user_sessions{admin="admin",method="ssh",profile="super_admin",srcaddr="198.51.100.23"} 1
+	fortigate_admin_user_sessions{admin="netops",method="https",profile="prof_admin",srcaddr="10.20.0.6"} 2
+	# HELP fortigate_banned_ip_expiry_timestamp_seconds Unix timestamp at which the ban of a source IP ends, not exported for bans that do not expire
+	# TYPE fortigate_banned_ip_expiry_timestamp_seconds gauge
+	fortigate_banned_ip_expiry_timestamp_seconds{ip="2001:db8::17",source="ips"} 1.5903459e+09
+	fortigate_banned_ip_expiry_timestamp_seconds{ip="203.0.113.7",source="ips"} 1.5903487e+09
+	`
+	if err := testutil.GatherAndCompare(r, strings.NewReader(em), "fortigate_admin_user_sessions", "fortigate_banned_ip_expiry_timestamp_seconds"); err != nil {
+		t.Fatalf("metric compare: err %v", err)
+	}
+}
+
+func TestSystemAdminSessionsNoBannedAccess(t *testing.T) {
+	c := newFakeClient()
+	c.prepare("api/v2/monitor/system/current-admins", "testdata/current-admins.jsonnet")
+	c.prepareError("api/v2/monitor/user/banned/select", errors.New("permission denied"))
+	config.MustReInit()
+	m, ok := probeSystemAdminSessions(c, &TargetMetadata{VersionMajor: 7, VersionMinor: 4})
+	if ok {
+		t.Errorf("probeSystemAdminSessions() returned success with failed banned IP request")
+	}
+	if len(m) != 3 {
+		t.Errorf("probeSystemAdminSessions() returned %d metrics, want the 3 session counts", len(m))
+	}
+}
+
+func TestSystemAdminSessionsBannedIPLimit(t *testing.T) {
+	c := newFakeClient()
+	c.prepare("api/v2/monitor/system/current-admins", "testdata/current-admins.jsonnet")
+	c.prepare("api/v2/monitor/user/banned/select", "testdata/user-banned.jsonnet")
+	r := prometheus.NewPedanticRegistry()
+	flag.Set("max-admin-sessions", "10")
+	defer flag.Set("max-admin-sessions", "0")
+	flag.Set("max-banned-ips", "2")
+	defer flag.Set("max-banned-ips", "0")
+	config.MustReInit()
+	if !testProbe(probeSystemAdminSessions, c, r) {
+		t.Errorf("probeSystemAdminSessions() returned non-success")
+	}
+
+	if n := testutil.CollectAndCount(r, "fortigate_banned_ip_expiry_timestamp_seconds"); n != 0 {
+		t.Errorf("probeSystemAdminSessions() returned %d banned IP expiries with more banned IPs than allowed", n)
+	}
+	if n := testutil.CollectAndCount(r, "fortigate_admin_user_sessions"); n != 4 {
+		t.Errorf("probeSystemAdminSessions() returned %d admin user sessions, want 4", n)
+	}
+}
@@ -14,8 +14,6 @@
 package probe
 
 import (
-	"log"
-
 	"github.com/prometheus-community/fortigate_exporter/pkg/http"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -61,27 +59,28 @@ func probeSystemAvailableCertificates(c http.FortiHTTP, meta *TargetMetadata) ([
 		Scope   string
 	}
 
-	var globalResponse Response
-	if err := c.Get("api/v2/monitor/system/available-certificates", "scope=global", &globalResponse); err != nil {
-		log.Printf("Error: %v", err)
-		return nil, false
-	}
-	globalResponse.Scope = "global"
+	// Return the certificates of the scope that could be fetched if the other one fails
+	ok := true
+	combinedResponses := make([]Response, 0)
 
 	var vdomResponses []Response
-
 	if err := c.Get("api/v2/monitor/system/available-certificates", "vdom=*", &vdomResponses); err != nil {
-		log.Printf("Error: %v", err)
-		return nil, false
+		meta.Logger().Error("API request failed", "err", err)
+		ok = false
 	}
 	for i := range vdomResponses {
 		vdomResponses[i].Scope = "vdom"
 	}
-
-	// combine responses
-	combinedResponses := make([]Response, 0)
 	combinedResponses = append(combinedResponses, vdomResponses...)
-	combinedResponses = append(combinedResponses, globalResponse)
+
+	var globalResponse Response
+	if err := c.Get("api/v2/monitor/system/available-certificates", "scope=global", &globalResponse); err != nil {
+		meta.Logger().Error("API request failed", "err", err)
+		ok = false
+	} else {
+		globalResponse.Scope = "global"
+		combinedResponses = append(combinedResponses, globalResponse)
+	}
 
 	m := []prometheus.Metric{}
 
@@ -93,5 +92,5 @@ func probeSystemAvailableCertificates(c http.FortiHTTP, meta *TargetMetadata) ([
 			m = append(m, prometheus.MustNewConstMetric(certificateCMDBReferences, prometheus.GaugeValue, result.QRef, result.Name, result.Source, response.Scope, response.VDOM))
 		}
 	}
-	return m, true
+	return m, ok
 }
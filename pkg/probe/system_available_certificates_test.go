@@ -14,6 +14,7 @@
 package probe
 
 import (
+	"errors"
 	"strings"
 	"testing"
 
@@ -108,3 +109,24 @@ func TestCertificates(t *testing.T) {
 		t.Fatalf("metric compare: err %v", err)
 	}
 }
+
+func TestCertificatesPartial(t *testing.T) {
+	c := newFakeClient()
+	c.prepareError("api/v2/monitor/system/available-certificates?scope=global", errors.New("connection reset"))
+	c.prepare("api/v2/monitor/system/available-certificates?vdom=*", "testdata/available-certificates-vdom.jsonnet")
+	m, ok := probeSystemAvailableCertificates(c, &TargetMetadata{VersionMajor: 7, VersionMinor: 4})
+	if ok {
+		t.Errorf("probeSystemAvailableCertificates() returned success with failed global request")
+	}
+	r := prometheus.NewPedanticRegistry()
+	r.MustRegister(&testProbeCollector{metrics: m})
+
+	em := `
+        # HELP fortigate_certificate_cmdb_references Number of times the certificate is referenced
+        # TYPE fortigate_certificate_cmdb_references gauge
+        fortigate_certificate_cmdb_references{name="Fortinet_CA_SSL",scope="vdom",source="factory",vdom="root"} 5
+	`
+	if err := testutil.GatherAndCompare(r, strings.NewReader(em), "fortigate_certificate_cmdb_references"); err != nil {
+		t.Fatalf("metric compare: err %v", err)
+	}
+}
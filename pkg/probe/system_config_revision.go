@@ -0,0 +1,121 @@
+// Copyright 2025 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package probe
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/prometheus-community/fortigate_exporter/pkg/http"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type ConfigRevision struct {
+	ID      int     `json:"id"`
+	Time    float64 `json:"time"`
+	Admin   string  `json:"admin"`
+	Comment string  `json:"comment"`
+}
+
+type ConfigRevisionResults struct {
+	Revisions            []ConfigRevision `json:"revisions"`
+	CurrentConfigUnsaved bool             `json:"current_config_unsaved"`
+}
+
+type ConfigRevisionResponse struct {
+	Results ConfigRevisionResults `json:"results"`
+}
+
+type ConfigChecksumResults struct {
+	Checksum map[string]string `json:"checksum"`
+	SerialNo string            `json:"serial_no"`
+}
+
+type ConfigChecksumResponse struct {
+	Results []ConfigChecksumResults `json:"results"`
+}
+
+func probeSystemConfigRevision(c http.FortiHTTP, meta *TargetMetadata) ([]prometheus.Metric, bool) {
+	var (
+		revisions = prometheus.NewDesc(
+			"fortigate_config_revisions",
+			"Number of saved configuration revisions",
+			[]string{}, nil,
+		)
+		lastChange = prometheus.NewDesc(
+			"fortigate_config_last_change_timestamp_seconds",
+			"Unix timestamp of the latest saved configuration revision",
+			[]string{}, nil,
+		)
+		revisionInfo = prometheus.NewDesc(
+			"fortigate_config_revision_info",
+			"Latest saved configuration revision and the admin that saved it",
+			[]string{"revision", "admin"}, nil,
+		)
+		unsaved = prometheus.NewDesc(
+			"fortigate_config_unsaved",
+			"Whether the running configuration has changes that are not saved as a revision",
+			[]string{}, nil,
+		)
+		checksum = prometheus.NewDesc(
+			"fortigate_config_checksum_info",
+			"Checksum of the configuration per scope, changes whenever the configuration changes",
+			[]string{"serial", "scope", "checksum"}, nil,
+		)
+	)
+
+	var res ConfigRevisionResponse
+	if err := c.Get("api/v2/monitor/system/config-revision", "", &res); err != nil {
+		meta.Logger().Error("API request failed", "err", err)
+		return nil, false
+	}
+
+	isUnsaved := 0.0
+	if res.Results.CurrentConfigUnsaved {
+		isUnsaved = 1.0
+	}
+	m := []prometheus.Metric{
+		prometheus.MustNewConstMetric(revisions, prometheus.GaugeValue, float64(len(res.Results.Revisions))),
+		prometheus.MustNewConstMetric(unsaved, prometheus.GaugeValue, isUnsaved),
+	}
+	if len(res.Results.Revisions) > 0 {
+		latest := res.Results.Revisions[0]
+		for _, r := range res.Results.Revisions[1:] {
+			if r.Time > latest.Time {
+				latest = r
+			}
+		}
+		m = append(m, prometheus.MustNewConstMetric(lastChange, prometheus.GaugeValue, latest.Time))
+		m = append(m, prometheus.MustNewConstMetric(revisionInfo, prometheus.GaugeValue, 1, strconv.Itoa(latest.ID), latest.Admin))
+	}
+
+	// The checksums are only informational, the probe does not fail without them
+	var cs ConfigChecksumResponse
+	if err := c.Get("api/v2/monitor/system/ha-checksums", "scope=global", &cs); err != nil {
+		meta.Logger().Warn("Failed to get configuration checksums", "err", err)
+		return m, true
+	}
+	for _, r := range cs.Results {
+		scopes := make([]string, 0, len(r.Checksum))
+		for s := range r.Checksum {
+			scopes = append(scopes, s)
+		}
+		sort.Strings(scopes)
+		for _, s := range scopes {
+			m = append(m, prometheus.MustNewConstMetric(checksum, prometheus.GaugeValue, 1, r.SerialNo, s, r.Checksum[s]))
+		}
+	}
+
+	return m, true
+}
@@ -0,0 +1,72 @@
+// Copyright 2025 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package probe
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestSystemConfigRevision(t *testing.T) {
+	c := newFakeClient()
+	c.prepare("api/v2/monitor/system/config-revision", "testdata/config-revision.jsonnet")
+	c.prepare("api/v2/monitor/system/ha-checksums", "testdata/ha-checksum.jsonnet")
+	r := prometheus.NewPedanticRegistry()
+	if !testProbe(probeSystemConfigRevision, c, r) {
+		t.Errorf("probeSystemConfigRevision() returned non-success")
+	}
+
+	em := `
+	# HELP fortigate_config_checksum_info Checksum of the configuration per scope, changes whenever the configuration changes
+	# TYPE fortigate_config_checksum_info gauge
+	fortigate_config_checksum_info{checksum="4df9cc121afe1c00de4e9e396af4cdb1",scope="all",serial="SERIAL111111111"} 1
+	fortigate_config_checksum_info{checksum="4df9cc121afe1c00de4e9e396af4cdb1",scope="all",serial="SERIAL222222222"} 1
+	fortigate_config_checksum_info{checksum="74cc1c60799e0a786ac7094b532f01b1",scope="root",serial="SERIAL111111111"} 1
+	fortigate_config_checksum_info{checksum="74cc1c60799e0a786ac7094b532f01b1",scope="root",serial="SERIAL222222222"} 1
+	fortigate_config_checksum_info{checksum="b6b3f51996c7da2d8adcbb44baa39750",scope="global",serial="SERIAL111111111"} 1
+	fortigate_config_checksum_info{checksum="b6b3f51996c7da2d8adcbb44baa39750",scope="global",serial="SERIAL222222222"} 1
+	# HELP fortigate_config_last_change_timestamp_seconds Unix timestamp of the latest saved configuration revision
+	# TYPE fortigate_config_last_change_timestamp_seconds gauge
+	fortigate_config_last_change_timestamp_seconds 1.590345408e+09
+	# HELP fortigate_config_revision_info Latest saved configuration revision and the admin that saved it
+	# TYPE fortigate_config_revision_info gauge
+	fortigate_config_revision_info{admin="bluecmd",revision="2"} 1
+	# HELP fortigate_config_revisions Number of saved configuration revisions
+	# TYPE fortigate_config_revisions gauge
+	fortigate_config_revisions 2
+	# HELP fortigate_config_unsaved Whether the running configuration has changes that are not saved as a revision
+	# TYPE fortigate_config_unsaved gauge
+	fortigate_config_unsaved 1
+	`
+	if err := testutil.GatherAndCompare(r, strings.NewReader(em)); err != nil {
+		t.Fatalf("metric compare: err %v", err)
+	}
+}
+
+func TestSystemConfigRevisionNoChecksums(t *testing.T) {
+	c := newFakeClient()
+	c.prepare("api/v2/monitor/system/config-revision", "testdata/config-revision.jsonnet")
+	c.prepareError("api/v2/monitor/system/ha-checksums", errors.New("permission denied"))
+	m, ok := probeSystemConfigRevision(c, &TargetMetadata{VersionMajor: 7, VersionMinor: 4})
+	if !ok {
+		t.Errorf("probeSystemConfigRevision() returned non-success without checksums")
+	}
+	if len(m) != 4 {
+		t.Errorf("probeSystemConfigRevision() returned %d metrics, want the 4 revision metrics", len(m))
+	}
+}
@@ -0,0 +1,216 @@
+// Copyright 2025 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package probe
+
+import (
+	"strconv"
+
+	"github.com/prometheus-community/fortigate_exporter/pkg/http"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type NTPServer struct {
+	Server    string  `json:"server"`
+	IP        string  `json:"ip"`
+	Reachable bool    `json:"reachable"`
+	Selected  bool    `json:"selected"`
+	Stratum   float64 `json:"stratum"`
+	Offset    float64 `json:"offset"`
+	Delay     float64 `json:"delay"`
+}
+
+type NTPStatus struct {
+	Results []NTPServer `json:"results"`
+}
+
+type DNSLatency struct {
+	Service string `json:"service"`
+	IP      string `json:"ip"`
+	// Missing or negative if the server did not answer
+	Latency    *float64 `json:"latency"`
+	LastUpdate float64  `json:"last_update"`
+}
+
+type DNSLatencies struct {
+	Results []DNSLatency `json:"results"`
+}
+
+type FortiGuardServer struct {
+	IP        string  `json:"ip"`
+	Weight    float64 `json:"weight"`
+	RTT       float64 `json:"rtt"`
+	Flags     string  `json:"flags"`
+	CurrLost  float64 `json:"curr_lost"`
+	TotalLost float64 `json:"total_lost"`
+}
+
+type FortiGuardServerInfo struct {
+	Protocol   string             `json:"protocol"`
+	Port       int                `json:"port"`
+	ServerList []FortiGuardServer `json:"server_list"`
+}
+
+type FortiGuardServerInfoResponse struct {
+	Results FortiGuardServerInfo `json:"results"`
+}
+
+func probeSystemConnectivity(c http.FortiHTTP, meta *TargetMetadata) ([]prometheus.Metric, bool) {
+	var (
+		ntpSynced = prometheus.NewDesc(
+			"fortigate_ntp_synchronized",
+			"Whether the system clock is synchronized to one of the NTP servers",
+			[]string{}, nil,
+		)
+		ntpReachable = prometheus.NewDesc(
+			"fortigate_ntp_server_reachable",
+			"Whether the NTP server is reachable",
+			[]string{"server", "ip"}, nil,
+		)
+		ntpSelected = prometheus.NewDesc(
+			"fortigate_ntp_server_selected",
+			"Whether the NTP server is the one the clock is synchronized to",
+			[]string{"server", "ip"}, nil,
+		)
+		ntpStratum = prometheus.NewDesc(
+			"fortigate_ntp_server_stratum",
+			"Stratum of the NTP server",
+			[]string{"server", "ip"}, nil,
+		)
+		ntpOffset = prometheus.NewDesc(
+			"fortigate_ntp_server_offset_seconds",
+			"Offset of the system clock to the NTP server",
+			[]string{"server", "ip"}, nil,
+		)
+		ntpDelay = prometheus.NewDesc(
+			"fortigate_ntp_server_delay_seconds",
+			"Round trip delay to the NTP server",
+			[]string{"server", "ip"}, nil,
+		)
+		dnsUp = prometheus.NewDesc(
+			"fortigate_dns_server_up",
+			"Whether the DNS server answered the latest latency measurement of FortiOS",
+			[]string{"service", "ip"}, nil,
+		)
+		dnsLatency = prometheus.NewDesc(
+			"fortigate_dns_server_latency_seconds",
+			"Latency of the DNS server as measured by FortiOS",
+			[]string{"service", "ip"}, nil,
+		)
+		dnsLastUpdate = prometheus.NewDesc(
+			"fortigate_dns_server_last_update_timestamp_seconds",
+			"Unix timestamp of the latest latency measurement of the DNS server",
+			[]string{"service", "ip"}, nil,
+		)
+		fgdServers = prometheus.NewDesc(
+			"fortigate_fortiguard_servers",
+			"Number of FortiGuard servers known to the FortiGate",
+			[]string{"protocol", "port"}, nil,
+		)
+		fgdInfo = prometheus.NewDesc(
+			"fortigate_fortiguard_server_info",
+			"Information about a FortiGuard server, flags as shown by 'diagnose debug rating'",
+			[]string{"ip", "flags"}, nil,
+		)
+		fgdRTT = prometheus.NewDesc(
+			"fortigate_fortiguard_server_rtt_seconds",
+			"Round trip time to the FortiGuard server",
+			[]string{"ip"}, nil,
+		)
+		fgdWeight = prometheus.NewDesc(
+			"fortigate_fortiguard_server_weight",
+			"Weight of the FortiGuard server for server selection, lower is preferred",
+			[]string{"ip"}, nil,
+		)
+		fgdCurrLost = prometheus.NewDesc(
+			"fortigate_fortiguard_server_consecutive_lost_packets",
+			"Number of consecutive packets lost to the FortiGuard server",
+			[]string{"ip"}, nil,
+		)
+		fgdTotalLost = prometheus.NewDesc(
+			"fortigate_fortiguard_server_lost_packets_total",
+			"Number of packets lost to the FortiGuard server",
+			[]string{"ip"}, nil,
+		)
+	)
+
+	// Each of the checks needs its own permission, a failing one only leaves
+	// out its metrics
+	ok := true
+	m := []prometheus.Metric{}
+
+	var ntp NTPStatus
+	if err := c.Get("api/v2/monitor/system/ntp/status", "", &ntp); err != nil {
+		meta.Logger().Error("API request failed", "err", err)
+		ok = false
+	} else {
+		synced := 0.0
+		for _, s := range ntp.Results {
+			reachable, selected := 0.0, 0.0
+			if s.Reachable {
+				reachable = 1.0
+			}
+			if s.Selected {
+				selected, synced = 1.0, 1.0
+			}
+			m = append(m, prometheus.MustNewConstMetric(ntpReachable, prometheus.GaugeValue, reachable, s.Server, s.IP))
+			m = append(m, prometheus.MustNewConstMetric(ntpSelected, prometheus.GaugeValue, selected, s.Server, s.IP))
+			if !s.Reachable {
+				continue
+			}
+			m = append(m, prometheus.MustNewConstMetric(ntpStratum, prometheus.GaugeValue, s.Stratum, s.Server, s.IP))
+			m = append(m, prometheus.MustNewConstMetric(ntpOffset, prometheus.GaugeValue, s.Offset*0.001, s.Server, s.IP))
+			m = append(m, prometheus.MustNewConstMetric(ntpDelay, prometheus.GaugeValue, s.Delay*0.001, s.Server, s.IP))
+		}
+		m = append(m, prometheus.MustNewConstMetric(ntpSynced, prometheus.GaugeValue, synced))
+	}
+
+	var dns DNSLatencies
+	if err := c.Get("api/v2/monitor/network/dns/latency", "", &dns); err != nil {
+		meta.Logger().Error("API request failed", "err", err)
+		ok = false
+	} else {
+		for _, s := range dns.Results {
+			up := 0.0
+			if s.Latency != nil && *s.Latency >= 0 {
+				up = 1.0
+				m = append(m, prometheus.MustNewConstMetric(dnsLatency, prometheus.GaugeValue, *s.Latency*0.001, s.Service, s.IP))
+			}
+			m = append(m, prometheus.MustNewConstMetric(dnsUp, prometheus.GaugeValue, up, s.Service, s.IP))
+			if s.LastUpdate > 0 {
+				m = append(m, prometheus.MustNewConstMetric(dnsLastUpdate, prometheus.GaugeValue, s.LastUpdate, s.Service, s.IP))
+			}
+		}
+	}
+
+	var fgd FortiGuardServerInfoResponse
+	if err := c.Get("api/v2/monitor/system/fortiguard/server-info", "", &fgd); err != nil {
+		meta.Logger().Error("API request failed", "err", err)
+		ok = false
+	} else {
+		port := ""
+		if fgd.Results.Port != 0 {
+			port = strconv.Itoa(fgd.Results.Port)
+		}
+		m = append(m, prometheus.MustNewConstMetric(fgdServers, prometheus.GaugeValue, float64(len(fgd.Results.ServerList)), fgd.Results.Protocol, port))
+		for _, s := range fgd.Results.ServerList {
+			m = append(m, prometheus.MustNewConstMetric(fgdInfo, prometheus.GaugeValue, 1, s.IP, s.Flags))
+			m = append(m, prometheus.MustNewConstMetric(fgdRTT, prometheus.GaugeValue, s.RTT*0.001, s.IP))
+			m = append(m, prometheus.MustNewConstMetric(fgdWeight, prometheus.GaugeValue, s.Weight, s.IP))
+			m = append(m, prometheus.MustNewConstMetric(fgdCurrLost, prometheus.GaugeValue, s.CurrLost, s.IP))
+			m = append(m, prometheus.MustNewConstMetric(fgdTotalLost, prometheus.CounterValue, s.TotalLost, s.IP))
+		}
+	}
+
+	return m, ok
+}
@@ -0,0 +1,133 @@
+// Copyright 2025 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package probe
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestSystemConnectivity(t *testing.T) {
+	c := newFakeClient()
+	c.prepare("api/v2/monitor/system/ntp/status", "testdata/ntp-status.jsonnet")
+	c.prepare("api/v2/monitor/network/dns/latency", "testdata/dns-latency.jsonnet")
+	c.prepare("api/v2/monitor/system/fortiguard/server-info", "testdata/fortiguard-server-info.jsonnet")
+	r := prometheus.NewPedanticRegistry()
+	if !testProbe(probeSystemConnectivity, c, r) {
+		t.Errorf("probeSystemConnectivity() returned non-success")
+	}
+
+	em := `
+	# HELP fortigate_dns_server_last_update_timestamp_seconds Unix timestamp of the latest latency measurement of the DNS server
+	# TYPE fortigate_dns_server_last_update_timestamp_seconds gauge
+	fortigate_dns_server_last_update_timestamp_seconds{ip="192.0.2.53",service="dns"} 1.59034554e+09
+	fortigate_dns_server_last_update_timestamp_seconds{ip="96.45.45.45",service="dns"} 1.59034554e+09
+	fortigate_dns_server_last_update_timestamp_seconds{ip="96.45.46.46",service="dns"} 1.59034554e+09
+	# HELP fortigate_dns_server_latency_seconds Latency of the DNS server as measured by FortiOS
+	# TYPE fortigate_dns_server_latency_seconds gauge
+	fortigate_dns_server_latency_seconds{ip="96.45.45.45",service="dns"} 0.011
+	fortigate_dns_server_latency_seconds{ip="96.45.46.46",service="dns"} 0.014
+	# HELP fortigate_dns_server_up Whether the DNS server answered the latest latency measurement of FortiOS
+	# TYPE fortigate_dns_server_up gauge
+	fortigate_dns_server_up{ip="173.243.138.225",service="ddns"} 0
+	fortigate_dns_server_up{ip="192.0.2.53",service="dns"} 0
+	fortigate_dns_server_up{ip="96.45.45.45",service="dns"} 1
+	fortigate_dns_server_up{ip="96.45.46.46",service="dns"} 1
+	# HELP fortigate_fortiguard_server_info Information about a FortiGuard server, flags as shown by 'diagnose debug rating'
+	# TYPE fortigate_fortiguard_server_info gauge
+	fortigate_fortiguard_server_info{flags="",ip="173.243.140.16"} 1
+	fortigate_fortiguard_server_info{flags="DI",ip="173.243.141.16"} 1
+	fortigate_fortiguard_server_info{flags="F",ip="12.34.97.18"} 1
+	# HELP fortigate_fortiguard_server_consecutive_lost_packets Number of consecutive packets lost to the FortiGuard server
+	# TYPE fortigate_fortiguard_server_consecutive_lost_packets gauge
+	fortigate_fortiguard_server_consecutive_lost_packets{ip="12.34.97.18"} 4
+	fortigate_fortiguard_server_consecutive_lost_packets{ip="173.243.140.16"} 0
+	fortigate_fortiguard_server_consecutive_lost_packets{ip="173.243.141.16"} 0
+	# HELP fortigate_fortiguard_server_lost_packets_total Number of packets lost to the FortiGuard server
+	# TYPE fortigate_fortiguard_server_lost_packets_total counter
+	fortigate_fortiguard_server_lost_packets_total{ip="12.34.97.18"} 27
+	fortigate_fortiguard_server_lost_packets_total{ip="173.243.140.16"} 3
+	fortigate_fortiguard_server_lost_packets_total{ip="173.243.141.16"} 0
+	# HELP fortigate_fortiguard_server_rtt_seconds Round trip time to the FortiGuard server
+	# TYPE fortigate_fortiguard_server_rtt_seconds gauge
+	fortigate_fortiguard_server_rtt_seconds{ip="12.34.97.18"} 0.08700000000000001
+	fortigate_fortiguard_server_rtt_seconds{ip="173.243.140.16"} 0.024
+	fortigate_fortiguard_server_rtt_seconds{ip="173.243.141.16"} 0.021
+	# HELP fortigate_fortiguard_server_weight Weight of the FortiGuard server for server selection, lower is preferred
+	# TYPE fortigate_fortiguard_server_weight gauge
+	fortigate_fortiguard_server_weight{ip="12.34.97.18"} 20
+	fortigate_fortiguard_server_weight{ip="173.243.140.16"} 10
+	fortigate_fortiguard_server_weight{ip="173.243.141.16"} 0
+	# HELP fortigate_fortiguard_servers Number of FortiGuard servers known to the FortiGate
+	# TYPE fortigate_fortiguard_servers gauge
+	fortigate_fortiguard_servers{port="443",protocol="https"} 3
+	# HELP fortigate_ntp_server_delay_seconds Round trip delay to the NTP server
+	# TYPE fortigate_ntp_server_delay_seconds gauge
+	fortigate_ntp_server_delay_seconds{ip="208.91.114.23",server="ntp1.fortiguard.com"} 0.018713
+	fortigate_ntp_server_delay_seconds{ip="208.91.114.24",server="ntp2.fortiguard.com"} 0.024031
+	# HELP fortigate_ntp_server_offset_seconds Offset of the system clock to the NTP server
+	# TYPE fortigate_ntp_server_offset_seconds gauge
+	fortigate_ntp_server_offset_seconds{ip="208.91.114.23",server="ntp1.fortiguard.com"} 0.000342
+	fortigate_ntp_server_offset_seconds{ip="208.91.114.24",server="ntp2.fortiguard.com"} -0.0012070000000000002
+	# HELP fortigate_ntp_server_reachable Whether the NTP server is reachable
+	# TYPE fortigate_ntp_server_reachable gauge
+	fortigate_ntp_server_reachable{ip="192.0.2.123",server="ntp.example.com"} 0
+	fortigate_ntp_server_reachable{ip="208.91.114.23",server="ntp1.fortiguard.com"} 1
+	fortigate_ntp_server_reachable{ip="208.91.114.24",server="ntp2.fortiguard.com"} 1
+	# HELP fortigate_ntp_server_selected Whether the NTP server is the one the clock is synchronized to
+	# TYPE fortigate_ntp_server_selected gauge
+	fortigate_ntp_server_selected{ip="192.0.2.123",server="ntp.example.com"} 0
+	fortigate_ntp_server_selected{ip="208.91.114.23",server="ntp1.fortiguard.com"} 1
+	fortigate_ntp_server_selected{ip="208.91.114.24",server="ntp2.fortiguard.com"} 0
+	# HELP fortigate_ntp_server_stratum Stratum of the NTP server
+	# TYPE fortigate_ntp_server_stratum gauge
+	fortigate_ntp_server_stratum{ip="208.91.114.23",server="ntp1.fortiguard.com"} 2
+	fortigate_ntp_server_stratum{ip="208.91.114.24",server="ntp2.fortiguard.com"} 2
+	# HELP fortigate_ntp_synchronized Whether the system clock is synchronized to one of the NTP servers
+	# TYPE fortigate_ntp_synchronized gauge
+	fortigate_ntp_synchronized 1
+	`
+	if err := testutil.GatherAndCompare(r, strings.NewReader(em)); err != nil {
+		t.Fatalf("metric compare: err %v", err)
+	}
+}
+
+func TestSystemConnectivityNoDNSAccess(t *testing.T) {
+	c := newFakeClient()
+	c.prepare("api/v2/monitor/system/ntp/status", "testdata/ntp-status.jsonnet")
+	c.prepareError("api/v2/monitor/network/dns/latency", errors.New("permission denied"))
+	c.prepare("api/v2/monitor/system/fortiguard/server-info", "testdata/fortiguard-server-info.jsonnet")
+	m, ok := probeSystemConnectivity(c, &TargetMetadata{VersionMajor: 7, VersionMinor: 4})
+	if ok {
+		t.Errorf("probeSystemConnectivity() returned success with failed DNS request")
+	}
+	r := prometheus.NewPedanticRegistry()
+	r.MustRegister(&testProbeCollector{metrics: m})
+
+	em := `
+	# HELP fortigate_fortiguard_servers Number of FortiGuard servers known to the FortiGate
+	# TYPE fortigate_fortiguard_servers gauge
+	fortigate_fortiguard_servers{port="443",protocol="https"} 3
+	# HELP fortigate_ntp_synchronized Whether the system clock is synchronized to one of the NTP servers
+	# TYPE fortigate_ntp_synchronized gauge
+	fortigate_ntp_synchronized 1
+	`
+	if err := testutil.GatherAndCompare(r, strings.NewReader(em), "fortigate_dns_server_up", "fortigate_fortiguard_servers", "fortigate_ntp_synchronized"); err != nil {
+		t.Fatalf("metric compare: err %v", err)
+	}
+}
@@ -0,0 +1,254 @@
+// Copyright 2025 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package probe
+
+import (
+	"math/big"
+	"net/netip"
+	"strconv"
+	"time"
+
+	"github.com/prometheus-community/fortigate_exporter/internal/config"
+	"github.com/prometheus-community/fortigate_exporter/pkg/http"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type DHCPLease struct {
+	IP         string  `json:"ip"`
+	Reserved   bool    `json:"reserved"`
+	MAC        string  `json:"mac"`
+	Hostname   string  `json:"hostname"`
+	ExpireTime float64 `json:"expire_time"`
+	Interface  string  `json:"interface"`
+	Type       string  `json:"type"`
+	ServerID   int     `json:"server_mkey"`
+}
+
+// ipVersion returns the label value of the address family of the lease
+func (l DHCPLease) ipVersion() string {
+	if l.Type == "" {
+		return "ipv4"
+	}
+	return l.Type
+}
+
+type DHCPLeases struct {
+	Results []DHCPLease `json:"results"`
+	VDOM    string      `json:"vdom"`
+}
+
+type DHCPRange struct {
+	StartIP string `json:"start-ip"`
+	EndIP   string `json:"end-ip"`
+}
+
+// name returns the label value of the range
+func (r DHCPRange) name() string {
+	return r.StartIP + "-" + r.EndIP
+}
+
+// size returns the number of addresses in the range, 0 if it is invalid
+func (r DHCPRange) size() float64 {
+	start, err1 := netip.ParseAddr(r.StartIP)
+	end, err2 := netip.ParseAddr(r.EndIP)
+	if err1 != nil || err2 != nil || start.BitLen() != end.BitLen() || end.Less(start) {
+		return 0
+	}
+	s, e := start.As16(), end.As16()
+	n := new(big.Int).Sub(new(big.Int).SetBytes(e[:]), new(big.Int).SetBytes(s[:]))
+	f, _ := new(big.Float).SetInt(n.Add(n, big.NewInt(1))).Float64()
+	return f
+}
+
+// contains returns true if ip is part of the range
+func (r DHCPRange) contains(ip netip.Addr) bool {
+	start, err1 := netip.ParseAddr(r.StartIP)
+	end, err2 := netip.ParseAddr(r.EndIP)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	return start.Compare(ip) <= 0 && ip.Compare(end) <= 0
+}
+
+type DHCPServer struct {
+	ID        int         `json:"id"`
+	Interface string      `json:"interface"`
+	IPRange   []DHCPRange `json:"ip-range"`
+}
+
+type DHCPServers struct {
+	Results []DHCPServer `json:"results"`
+	VDOM    string       `json:"vdom"`
+}
+
+// dhcpExpiryWindows are the upper bounds of fortigate_dhcp_leases_expiring
+var dhcpExpiryWindows = []struct {
+	name string
+	d    time.Duration
+}{
+	{"5m", 5 * time.Minute},
+	{"1h", time.Hour},
+	{"4h", 4 * time.Hour},
+	{"1d", 24 * time.Hour},
+}
+
+func probeSystemDHCP(c http.FortiHTTP, meta *TargetMetadata) ([]prometheus.Metric, bool) {
+	savedConfig := config.GetConfig()
+	MaxDHCPLeases := savedConfig.MaxDHCPLeases
+
+	var (
+		leases = prometheus.NewDesc(
+			"fortigate_dhcp_leases",
+			"Number of DHCP leases in use by server, interface and IP range",
+			[]string{"vdom", "server", "interface", "ip_version", "range"}, nil,
+		)
+		reservedLeases = prometheus.NewDesc(
+			"fortigate_dhcp_reserved_leases",
+			"Number of DHCP leases in use of reserved addresses by server, interface and IP range",
+			[]string{"vdom", "server", "interface", "ip_version", "range"}, nil,
+		)
+		rangeSize = prometheus.NewDesc(
+			"fortigate_dhcp_range_addresses",
+			"Number of addresses in the IP range of a DHCP server",
+			[]string{"vdom", "server", "interface", "ip_version", "range"}, nil,
+		)
+		expiring = prometheus.NewDesc(
+			"fortigate_dhcp_leases_expiring",
+			"Number of DHCP leases expiring within the given time by server and interface",
+			[]string{"vdom", "server", "interface", "ip_version", "within"}, nil,
+		)
+		leaseInfo = prometheus.NewDesc(
+			"fortigate_dhcp_lease_info",
+			"Information about a DHCP lease in use",
+			[]string{"vdom", "server", "interface", "ip_version", "ip", "mac", "hostname"}, nil,
+		)
+	)
+
+	// ipv6=true returns the IPv6 leases in addition to the IPv4 ones
+	var res []DHCPLeases
+	if err := c.Get("api/v2/monitor/system/dhcp", "vdom=*&ipv6=true", &res); err != nil {
+		meta.Logger().Error("API request failed", "err", err)
+		return nil, false
+	}
+
+	// Without the server configuration the leases are not split by range and
+	// the range sizes are left out
+	ok := true
+	var srv4, srv6 []DHCPServers
+	if err := c.Get("api/v2/cmdb/system.dhcp/server", "vdom=*", &srv4); err != nil {
+		meta.Logger().Error("API request failed", "err", err)
+		ok = false
+	}
+	if err := c.Get("api/v2/cmdb/system.dhcp6/server", "vdom=*", &srv6); err != nil {
+		meta.Logger().Error("API request failed", "err", err)
+		ok = false
+	}
+
+	type serverKey struct {
+		VDOM      string
+		ID        string
+		IPVersion string
+	}
+	servers := map[serverKey]DHCPServer{}
+	for ipVersion, srvs := range map[string][]DHCPServers{"ipv4": srv4, "ipv6": srv6} {
+		for _, r := range srvs {
+			for _, s := range r.Results {
+				servers[serverKey{r.VDOM, strconv.Itoa(s.ID), ipVersion}] = s
+			}
+		}
+	}
+
+	type rangeKey struct {
+		serverKey
+		Interface string
+		Range     string
+	}
+	type expiryKey struct {
+		serverKey
+		Interface string
+		Within    string
+	}
+	type rangeCount struct {
+		InUse    float64
+		Reserved float64
+	}
+	counts := map[rangeKey]*rangeCount{}
+	expiringCounts := map[expiryKey]float64{}
+	total := 0
+	now := meta.Now()
+
+	m := []prometheus.Metric{}
+	// Report every configured range, also the ones without any lease
+	for k, s := range servers {
+		for _, ipr := range s.IPRange {
+			counts[rangeKey{k, s.Interface, ipr.name()}] = &rangeCount{}
+			m = append(m, prometheus.MustNewConstMetric(rangeSize, prometheus.GaugeValue, ipr.size(), k.VDOM, k.ID, s.Interface, k.IPVersion, ipr.name()))
+		}
+	}
+	for _, r := range res {
+		total += len(r.Results)
+		for _, l := range r.Results {
+			sk := serverKey{r.VDOM, strconv.Itoa(l.ServerID), l.ipVersion()}
+			rk := rangeKey{sk, l.Interface, ""}
+			if ip, err := netip.ParseAddr(l.IP); err == nil {
+				for _, ipr := range servers[sk].IPRange {
+					if ipr.contains(ip) {
+						rk.Range = ipr.name()
+						break
+					}
+				}
+			}
+			if counts[rk] == nil {
+				counts[rk] = &rangeCount{}
+			}
+			counts[rk].InUse++
+			if l.Reserved {
+				counts[rk].Reserved++
+			}
+			// Leases without an expiry, e.g. of reserved addresses, and the
+			// ones past it are not expiring
+			expiry := time.Unix(int64(l.ExpireTime), 0)
+			pending := l.ExpireTime > 0 && expiry.After(now)
+			for _, w := range dhcpExpiryWindows {
+				ek := expiryKey{sk, l.Interface, w.name}
+				expiringCounts[ek] += 0
+				if pending && expiry.Sub(now) <= w.d {
+					expiringCounts[ek]++
+				}
+			}
+		}
+	}
+
+	for k, v := range counts {
+		m = append(m, prometheus.MustNewConstMetric(leases, prometheus.GaugeValue, v.InUse, k.VDOM, k.ID, k.Interface, k.IPVersion, k.Range))
+		m = append(m, prometheus.MustNewConstMetric(reservedLeases, prometheus.GaugeValue, v.Reserved, k.VDOM, k.ID, k.Interface, k.IPVersion, k.Range))
+	}
+	for k, v := range expiringCounts {
+		m = append(m, prometheus.MustNewConstMetric(expiring, prometheus.GaugeValue, v, k.VDOM, k.ID, k.Interface, k.IPVersion, k.Within))
+	}
+
+	// Per lease metrics are opt-in as every client is a series
+	if MaxDHCPLeases != 0 {
+		if total > MaxDHCPLeases {
+			meta.Logger().Error("Received more DHCP leases than maximum allowed, ignoring metric", "leases", total, "max", MaxDHCPLeases)
+		} else {
+			for _, r := range res {
+				for _, l := range r.Results {
+					m = append(m, prometheus.MustNewConstMetric(leaseInfo, prometheus.GaugeValue, 1, r.VDOM, strconv.Itoa(l.ServerID), l.Interface, l.ipVersion(), l.IP, l.MAC, l.Hostname))
+				}
+			}
+		}
+	}
+	return m, ok
+}
@@ -0,0 +1,196 @@
+// Copyright 2025 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package probe
+
+import (
+	"errors"
+	"flag"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus-community/fortigate_exporter/internal/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func prepareDHCP(c *fakeClient) {
+	c.prepare("api/v2/monitor/system/dhcp?vdom=*&ipv6=true", "testdata/dhcp-leases.jsonnet")
+	c.prepare("api/v2/cmdb/system.dhcp/server?vdom=*", "testdata/dhcp-server.jsonnet")
+	c.prepare("api/v2/cmdb/system.dhcp6/server?vdom=*", "testdata/dhcp6-server.jsonnet")
+}
+
+// dhcpMetadata gives the tests a stable lease expiry distribution
+func dhcpMetadata() *TargetMetadata {
+	return &TargetMetadata{
+		VersionMajor: 7,
+		VersionMinor: 4,
+		now:          func() time.Time { return time.Unix(1590345600, 0) },
+	}
+}
+
+func TestSystemDHCP(t *testing.T) {
+	c := newFakeClient()
+	prepareDHCP(c)
+	r := prometheus.NewPedanticRegistry()
+	config.MustReInit()
+	if !testProbeWithMetadata(probeSystemDHCP, c, dhcpMetadata(), r) {
+		t.Errorf("probeSystemDHCP() returned non-success")
+	}
+
+	em := `
+	# HELP fortigate_dhcp_leases Number of DHCP leases in use by server, interface and IP range
+	# TYPE fortigate_dhcp_leases gauge
+	fortigate_dhcp_leases{interface="guest",ip_version="ipv4",range="10.20.0.10-10.20.0.60",server="2",vdom="root"} 1
+	fortigate_dhcp_leases{interface="guest",ip_version="ipv4",range="10.20.1.10-10.20.1.20",server="2",vdom="root"} 1
+	fortigate_dhcp_leases{interface="internal",ip_version="ipv4",range="",server="1",vdom="root"} 1
+	fortigate_dhcp_leases{interface="internal",ip_version="ipv4",range="192.168.1.110-192.168.1.210",server="1",vdom="root"} 4
+	fortigate_dhcp_leases{interface="internal",ip_version="ipv6",range="2001:db8:1::100-2001:db8:1::1ff",server="1",vdom="root"} 1
+	fortigate_dhcp_leases{interface="vlan-knx",ip_version="ipv4",range="10.30.0.2-10.30.0.14",server="1",vdom="knx"} 1
+	# HELP fortigate_dhcp_leases_expiring Number of DHCP leases expiring within the given time by server and interface
+	# TYPE fortigate_dhcp_leases_expiring gauge
+	fortigate_dhcp_leases_expiring{interface="guest",ip_version="ipv4",server="2",vdom="root",within="1d"} 2
+	fortigate_dhcp_leases_expiring{interface="guest",ip_version="ipv4",server="2",vdom="root",within="1h"} 1
+	fortigate_dhcp_leases_expiring{interface="guest",ip_version="ipv4",server="2",vdom="root",within="4h"} 1
+	fortigate_dhcp_leases_expiring{interface="guest",ip_version="ipv4",server="2",vdom="root",within="5m"} 0
+	fortigate_dhcp_leases_expiring{interface="internal",ip_version="ipv4",server="1",vdom="root",within="1d"} 3
+	fortigate_dhcp_leases_expiring{interface="internal",ip_version="ipv4",server="1",vdom="root",within="1h"} 2
+	fortigate_dhcp_leases_expiring{interface="internal",ip_version="ipv4",server="1",vdom="root",within="4h"} 3
+	fortigate_dhcp_leases_expiring{interface="internal",ip_version="ipv4",server="1",vdom="root",within="5m"} 1
+	fortigate_dhcp_leases_expiring{interface="internal",ip_version="ipv6",server="1",vdom="root",within="1d"} 1
+	fortigate_dhcp_leases_expiring{interface="internal",ip_version="ipv6",server="1",vdom="root",within="1h"} 1
+	fortigate_dhcp_leases_expiring{interface="internal",ip_version="ipv6",server="1",vdom="root",within="4h"} 1
+	fortigate_dhcp_leases_expiring{interface="internal",ip_version="ipv6",server="1",vdom="root",within="5m"} 0
+	fortigate_dhcp_leases_expiring{interface="vlan-knx",ip_version="ipv4",server="1",vdom="knx",within="1d"} 0
+	fortigate_dhcp_leases_expiring{interface="vlan-knx",ip_version="ipv4",server="1",vdom="knx",within="1h"} 0
+	fortigate_dhcp_leases_expiring{interface="vlan-knx",ip_version="ipv4",server="1",vdom="knx",within="4h"} 0
+	fortigate_dhcp_leases_expiring{interface="vlan-knx",ip_version="ipv4",server="1",vdom="knx",within="5m"} 0
+	# HELP fortigate_dhcp_range_addresses Number of addresses in the IP range of a DHCP server
+	# TYPE fortigate_dhcp_range_addresses gauge
+	fortigate_dhcp_range_addresses{interface="guest",ip_version="ipv4",range="10.20.0.10-10.20.0.60",server="2",vdom="root"} 51
+	fortigate_dhcp_range_addresses{interface="guest",ip_version="ipv4",range="10.20.1.10-10.20.1.20",server="2",vdom="root"} 11
+	fortigate_dhcp_range_addresses{interface="internal",ip_version="ipv4",range="192.168.1.110-192.168.1.210",server="1",vdom="root"} 101
+	fortigate_dhcp_range_addresses{interface="internal",ip_version="ipv6",range="2001:db8:1::100-2001:db8:1::1ff",server="1",vdom="root"} 256
+	fortigate_dhcp_range_addresses{interface="vlan-knx",ip_version="ipv4",range="10.30.0.2-10.30.0.14",server="1",vdom="knx"} 13
+	# HELP fortigate_dhcp_reserved_leases Number of DHCP leases in use of reserved addresses by server, interface and IP range
+	# TYPE fortigate_dhcp_reserved_leases gauge
+	fortigate_dhcp_reserved_leases{interface="guest",ip_version="ipv4",range="10.20.0.10-10.20.0.60",server="2",vdom="root"} 0
+	fortigate_dhcp_reserved_leases{interface="guest",ip_version="ipv4",range="10.20.1.10-10.20.1.20",server="2",vdom="root"} 0
+	fortigate_dhcp_reserved_leases{interface="internal",ip_version="ipv4",range="",server="1",vdom="root"} 1
+	fortigate_dhcp_reserved_leases{interface="internal",ip_version="ipv4",range="192.168.1.110-192.168.1.210",server="1",vdom="root"} 1
+	fortigate_dhcp_reserved_leases{interface="internal",ip_version="ipv6",range="2001:db8:1::100-2001:db8:1::1ff",server="1",vdom="root"} 0
+	fortigate_dhcp_reserved_leases{interface="vlan-knx",ip_version="ipv4",range="10.30.0.2-10.30.0.14",server="1",vdom="knx"} 0
+	`
+	if err := testutil.GatherAndCompare(r, strings.NewReader(em)); err != nil {
+		t.Fatalf("metric compare: err %v", err)
+	}
+}
+
+func TestSystemDHCPLeaseInfo(t *testing.T) {
+	c := newFakeClient()
+	prepareDHCP(c)
+	r := prometheus.NewPedanticRegistry()
+	flag.Set("max-dhcp-leases", "100")
+	defer flag.Set("max-dhcp-leases", "0")
+	config.MustReInit()
+	if !testProbe(probeSystemDHCP, c, r) {
+		t.Errorf("probeSystemDHCP() returned non-success")
+	}
+
+	em := `
+	# HELP fortigate_dhcp_lease_info Information about a DHCP lease in use
+	# TYPE fortigate_dhcp_lease_info gauge
+	fortigate_dhcp_lease_info{hostname="",interface="guest",ip="10.20.1.12",ip_version="ipv4",mac="00:09:0f:bb:00:02",server="2",vdom="root"} 1
+	fortigate_dhcp_lease_info{hostname="",interface="internal",ip="2001:db8:1::100",ip_version="ipv6",mac="",server="1",vdom="root"} 1
+	fortigate_dhcp_lease_info{hostname="DESKTOP-4F2K1",interface="internal",ip="192.168.1.110",ip_version="ipv4",mac="00:09:0f:aa:00:01",server="1",vdom="root"} 1
+	fortigate_dhcp_lease_info{hostname="DESKTOP-9XK0P",interface="internal",ip="192.168.1.111",ip_version="ipv4",mac="00:09:0f:aa:00:02",server="1",vdom="root"} 1
+	fortigate_dhcp_lease_info{hostname="ap-lobby",interface="internal",ip="192.168.1.10",ip_version="ipv4",mac="00:09:0f:aa:00:05",server="1",vdom="root"} 1
+	fortigate_dhcp_lease_info{hostname="iPhone",interface="guest",ip="10.20.0.10",ip_version="ipv4",mac="00:09:0f:bb:00:01",server="2",vdom="root"} 1
+	fortigate_dhcp_lease_info{hostname="knx-gateway",interface="vlan-knx",ip="10.30.0.2",ip_version="ipv4",mac="00:09:0f:cc:00:01",server="1",vdom="knx"} 1
+	fortigate_dhcp_lease_info{hostname="nas",interface="internal",ip="192.168.1.150",ip_version="ipv4",mac="00:09:0f:aa:00:04",server="1",vdom="root"} 1
+	fortigate_dhcp_lease_info{hostname="printer-2nd-floor",interface="internal",ip="192.168.1.112",ip_version="ipv4",mac="00:09:0f:aa:00:03",server="1",vdom="root"} 1
+	`
+	if err := testutil.GatherAndCompare(r, strings.NewReader(em), "fortigate_dhcp_lease_info"); err != nil {
+		t.Fatalf("metric compare: err %v", err)
+	}
+}
+
+func TestSystemDHCPLeaseInfoLimit(t *testing.T) {
+	c := newFakeClient()
+	prepareDHCP(c)
+	flag.Set("max-dhcp-leases", "5")
+	defer flag.Set("max-dhcp-leases", "0")
+	config.MustReInit()
+	m, ok := probeSystemDHCP(c, &TargetMetadata{})
+	if !ok {
+		t.Errorf("probeSystemDHCP() returned non-success")
+	}
+	for _, metric := range m {
+		if strings.Contains(metric.Desc().String(), "fortigate_dhcp_lease_info") {
+			t.Fatalf("probeSystemDHCP() returned per lease metrics with more leases than allowed")
+		}
+	}
+}
+
+func TestSystemDHCPNoServerAccess(t *testing.T) {
+	c := newFakeClient()
+	c.prepare("api/v2/monitor/system/dhcp?vdom=*&ipv6=true", "testdata/dhcp-leases.jsonnet")
+	c.prepareError("api/v2/cmdb/system.dhcp/server?vdom=*", errors.New("permission denied"))
+	c.prepareError("api/v2/cmdb/system.dhcp6/server?vdom=*", errors.New("permission denied"))
+	config.MustReInit()
+	m, ok := probeSystemDHCP(c, &TargetMetadata{VersionMajor: 7, VersionMinor: 4})
+	if ok {
+		t.Errorf("probeSystemDHCP() returned success with failed server config requests")
+	}
+	r := prometheus.NewPedanticRegistry()
+	r.MustRegister(&testProbeCollector{metrics: m})
+
+	em := `
+	# HELP fortigate_dhcp_leases Number of DHCP leases in use by server, interface and IP range
+	# TYPE fortigate_dhcp_leases gauge
+	fortigate_dhcp_leases{interface="guest",ip_version="ipv4",range="",server="2",vdom="root"} 2
+	fortigate_dhcp_leases{interface="internal",ip_version="ipv4",range="",server="1",vdom="root"} 5
+	fortigate_dhcp_leases{interface="internal",ip_version="ipv6",range="",server="1",vdom="root"} 1
+	fortigate_dhcp_leases{interface="vlan-knx",ip_version="ipv4",range="",server="1",vdom="knx"} 1
+	`
+	if err := testutil.GatherAndCompare(r, strings.NewReader(em), "fortigate_dhcp_leases", "fortigate_dhcp_range_addresses"); err != nil {
+		t.Fatalf("metric compare: err %v", err)
+	}
+}
+
+func TestSystemDHCPExpired(t *testing.T) {
+	c := newFakeClient()
+	c.prepare("api/v2/monitor/system/dhcp?vdom=*&ipv6=true", "testdata/dhcp-leases-expired.jsonnet")
+	c.prepare("api/v2/cmdb/system.dhcp/server?vdom=*", "testdata/dhcp-server.jsonnet")
+	c.prepare("api/v2/cmdb/system.dhcp6/server?vdom=*", "testdata/dhcp6-server.jsonnet")
+	r := prometheus.NewPedanticRegistry()
+	config.MustReInit()
+	if !testProbeWithMetadata(probeSystemDHCP, c, dhcpMetadata(), r) {
+		t.Errorf("probeSystemDHCP() returned non-success")
+	}
+
+	// Only the lease expiring in 2 minutes counts, not the one without an
+	// expiry nor the one that expired 10 minutes ago
+	em := `
+	# HELP fortigate_dhcp_leases_expiring Number of DHCP leases expiring within the given time by server and interface
+	# TYPE fortigate_dhcp_leases_expiring gauge
+	fortigate_dhcp_leases_expiring{interface="internal",ip_version="ipv4",server="1",vdom="root",within="1d"} 1
+	fortigate_dhcp_leases_expiring{interface="internal",ip_version="ipv4",server="1",vdom="root",within="1h"} 1
+	fortigate_dhcp_leases_expiring{interface="internal",ip_version="ipv4",server="1",vdom="root",within="4h"} 1
+	fortigate_dhcp_leases_expiring{interface="internal",ip_version="ipv4",server="1",vdom="root",within="5m"} 1
+	`
+	if err := testutil.GatherAndCompare(r, strings.NewReader(em), "fortigate_dhcp_leases_expiring"); err != nil {
+		t.Fatalf("metric compare: err %v", err)
+	}
+}
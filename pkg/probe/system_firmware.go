@@ -0,0 +1,148 @@
+// Copyright 2025 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package probe
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/prometheus-community/fortigate_exporter/pkg/http"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type FirmwareVersion struct {
+	Version      string `json:"version"`
+	Major        int    `json:"major"`
+	Minor        int    `json:"minor"`
+	Patch        int    `json:"patch"`
+	Build        int    `json:"build"`
+	Maturity     string `json:"maturity"`
+	PlatformID   string `json:"platform-id"`
+	EndOfSupport string `json:"end-of-support"`
+}
+
+// newer returns true if v is a later release than o
+func (v FirmwareVersion) newer(o FirmwareVersion) bool {
+	if v.Major != o.Major {
+		return v.Major > o.Major
+	}
+	if v.Minor != o.Minor {
+		return v.Minor > o.Minor
+	}
+	if v.Patch != o.Patch {
+		return v.Patch > o.Patch
+	}
+	return v.Build > o.Build
+}
+
+func (v FirmwareVersion) train() string {
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+type FirmwareResults struct {
+	Current   FirmwareVersion   `json:"current"`
+	Available []FirmwareVersion `json:"available"`
+}
+
+type FirmwareResponse struct {
+	Results FirmwareResults `json:"results"`
+}
+
+func probeSystemFirmware(c http.FortiHTTP, meta *TargetMetadata) ([]prometheus.Metric, bool) {
+	var (
+		running = prometheus.NewDesc(
+			"fortigate_firmware_running_info",
+			"Firmware version running on the device",
+			[]string{"platform", "version", "build", "train"}, nil,
+		)
+		latest = prometheus.NewDesc(
+			"fortigate_firmware_latest_info",
+			"Newest firmware available in the running (patch) or the next (major) release train",
+			[]string{"upgrade", "version", "build", "train", "maturity"}, nil,
+		)
+		upgradeAvailable = prometheus.NewDesc(
+			"fortigate_firmware_upgrade_available",
+			"Whether a newer firmware is available in the running (patch) or the next (major) release train",
+			[]string{"upgrade"}, nil,
+		)
+		endOfSupport = prometheus.NewDesc(
+			"fortigate_firmware_end_of_support_timestamp_seconds",
+			"Unix timestamp at which the running firmware reaches end of support",
+			[]string{}, nil,
+		)
+		endOfSupportReached = prometheus.NewDesc(
+			"fortigate_firmware_end_of_support",
+			"Whether the running firmware has reached end of support",
+			[]string{}, nil,
+		)
+	)
+
+	var res FirmwareResponse
+	if err := c.Get("api/v2/monitor/system/firmware", "", &res); err != nil {
+		meta.Logger().Error("API request failed", "err", err)
+		return nil, false
+	}
+
+	cur := res.Results.Current
+	m := []prometheus.Metric{
+		prometheus.MustNewConstMetric(running, prometheus.GaugeValue, 1, cur.PlatformID, cur.Version, strconv.Itoa(cur.Build), cur.train()),
+	}
+
+	// patch is the newest release of the running train, major the newest
+	// release of the closest newer train
+	var patch, major *FirmwareVersion
+	for i := range res.Results.Available {
+		a := &res.Results.Available[i]
+		switch {
+		case a.train() == cur.train():
+			if a.newer(cur) && (patch == nil || a.newer(*patch)) {
+				patch = a
+			}
+		case a.newer(cur):
+			if major == nil || a.Major < major.Major || (a.Major == major.Major && a.Minor < major.Minor) ||
+				(a.train() == major.train() && a.newer(*major)) {
+				major = a
+			}
+		}
+	}
+	for _, u := range []struct {
+		name string
+		v    *FirmwareVersion
+	}{{"patch", patch}, {"major", major}} {
+		if u.v == nil {
+			m = append(m, prometheus.MustNewConstMetric(upgradeAvailable, prometheus.GaugeValue, 0, u.name))
+			continue
+		}
+		m = append(m, prometheus.MustNewConstMetric(upgradeAvailable, prometheus.GaugeValue, 1, u.name))
+		m = append(m, prometheus.MustNewConstMetric(latest, prometheus.GaugeValue, 1, u.name, u.v.Version, strconv.Itoa(u.v.Build), u.v.train(), u.v.Maturity))
+	}
+
+	// Only newer FortiOS versions return the end of support date
+	if cur.EndOfSupport != "" {
+		eos, err := time.Parse(time.DateOnly, cur.EndOfSupport)
+		if err != nil {
+			meta.Logger().Warn("Failed to parse end of support date", "date", cur.EndOfSupport, "err", err)
+		} else {
+			reached := 0.0
+			if !meta.Now().Before(eos) {
+				reached = 1.0
+			}
+			m = append(m, prometheus.MustNewConstMetric(endOfSupport, prometheus.GaugeValue, float64(eos.Unix())))
+			m = append(m, prometheus.MustNewConstMetric(endOfSupportReached, prometheus.GaugeValue, reached))
+		}
+	}
+
+	return m, true
+}
@@ -0,0 +1,90 @@
+// Copyright 2025 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package probe
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestSystemFirmware(t *testing.T) {
+	c := newFakeClient()
+	c.prepare("api/v2/monitor/system/firmware", "testdata/system-firmware.jsonnet")
+	r := prometheus.NewPedanticRegistry()
+	meta := &TargetMetadata{
+		VersionMajor: 7,
+		VersionMinor: 4,
+		now:          func() time.Time { return time.Unix(1761000000, 0) },
+	}
+	if !testProbeWithMetadata(probeSystemFirmware, c, meta, r) {
+		t.Errorf("probeSystemFirmware() returned non-success")
+	}
+
+	em := `
+	# HELP fortigate_firmware_end_of_support Whether the running firmware has reached end of support
+	# TYPE fortigate_firmware_end_of_support gauge
+	fortigate_firmware_end_of_support 1
+	# HELP fortigate_firmware_end_of_support_timestamp_seconds Unix timestamp at which the running firmware reaches end of support
+	# TYPE fortigate_firmware_end_of_support_timestamp_seconds gauge
+	fortigate_firmware_end_of_support_timestamp_seconds 1.7591040e+09
+	# HELP fortigate_firmware_latest_info Newest firmware available in the running (patch) or the next (major) release train
+	# TYPE fortigate_firmware_latest_info gauge
+	fortigate_firmware_latest_info{build="1639",maturity="M",train="7.2",upgrade="patch",version="v7.2.8"} 1
+	fortigate_firmware_latest_info{build="2662",maturity="M",train="7.4",upgrade="major",version="v7.4.4"} 1
+	# HELP fortigate_firmware_running_info Firmware version running on the device
+	# TYPE fortigate_firmware_running_info gauge
+	fortigate_firmware_running_info{build="1517",platform="FGT61F",train="7.2",version="v7.2.5"} 1
+	# HELP fortigate_firmware_upgrade_available Whether a newer firmware is available in the running (patch) or the next (major) release train
+	# TYPE fortigate_firmware_upgrade_available gauge
+	fortigate_firmware_upgrade_available{upgrade="major"} 1
+	fortigate_firmware_upgrade_available{upgrade="patch"} 1
+	`
+	if err := testutil.GatherAndCompare(r, strings.NewReader(em)); err != nil {
+		t.Fatalf("metric compare: err %v", err)
+	}
+}
+
+func TestSystemFirmwareEndOfSupport(t *testing.T) {
+	// The running firmware reaches end of support on 2025-09-29
+	for now, want := range map[time.Time]string{
+		time.Date(2025, 9, 28, 23, 59, 59, 0, time.UTC): "0",
+		time.Date(2025, 9, 29, 0, 0, 0, 0, time.UTC):    "1",
+		time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC):     "1",
+	} {
+		c := newFakeClient()
+		c.prepare("api/v2/monitor/system/firmware", "testdata/system-firmware.jsonnet")
+		r := prometheus.NewPedanticRegistry()
+		meta := &TargetMetadata{
+			VersionMajor: 7,
+			VersionMinor: 4,
+			now:          func() time.Time { return now },
+		}
+		if !testProbeWithMetadata(probeSystemFirmware, c, meta, r) {
+			t.Errorf("probeSystemFirmware() returned non-success")
+		}
+
+		em := `
+		# HELP fortigate_firmware_end_of_support Whether the running firmware has reached end of support
+		# TYPE fortigate_firmware_end_of_support gauge
+		fortigate_firmware_end_of_support ` + want + `
+		`
+		if err := testutil.GatherAndCompare(r, strings.NewReader(em), "fortigate_firmware_end_of_support"); err != nil {
+			t.Errorf("at %v: metric compare: err %v", now, err)
+		}
+	}
+}
@@ -14,6 +14,7 @@
 package probe
 
 import (
+	"errors"
 	"strings"
 	"testing"
 
@@ -126,3 +127,25 @@ func TestHAStatisticsNoConfigAccess(t *testing.T) {
 		t.Fatalf("metric compare: err %v", err)
 	}
 }
+
+func TestHAStatisticsPartial(t *testing.T) {
+	c := newFakeClient()
+	c.prepare("api/v2/monitor/system/ha-statistics", "testdata/ha-statistics.jsonnet")
+	c.prepareError("api/v2/cmdb/system/ha", errors.New("connection reset"))
+	m, ok := probeSystemHAStatistics(c, &TargetMetadata{VersionMajor: 7, VersionMinor: 4})
+	if ok {
+		t.Errorf("probeSystemHAStatistics() returned success with failed config request")
+	}
+	r := prometheus.NewPedanticRegistry()
+	r.MustRegister(&testProbeCollector{metrics: m})
+
+	em := `
+        # HELP fortigate_ha_member_sessions Sessions which are handled by this HA member
+        # TYPE fortigate_ha_member_sessions gauge
+        fortigate_ha_member_sessions{hostname="member-name-1",vdom="root"} 148
+        fortigate_ha_member_sessions{hostname="member-name-2",vdom="root"} 12
+	`
+	if err := testutil.GatherAndCompare(r, strings.NewReader(em), "fortigate_ha_member_info", "fortigate_ha_member_sessions"); err != nil {
+		t.Fatalf("metric compare: err %v", err)
+	}
+}
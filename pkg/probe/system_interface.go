@@ -14,7 +14,7 @@
 package probe
 
 import (
-	"log"
+	"strconv"
 
 	"github.com/prometheus-community/fortigate_exporter/pkg/http"
 	"github.com/prometheus/client_golang/prometheus"
@@ -62,6 +62,66 @@ func probeSystemInterface(c http.FortiHTTP, meta *TargetMetadata) ([]prometheus.
 			"Number of reception errors detected on the interface",
 			[]string{"vdom", "name", "alias", "parent"}, nil,
 		)
+		mDuplex = prometheus.NewDesc(
+			"fortigate_interface_full_duplex",
+			"Whether the link negotiated full duplex or not",
+			[]string{"vdom", "name", "alias", "parent"}, nil,
+		)
+		mTxDrop = prometheus.NewDesc(
+			"fortigate_interface_transmit_dropped_packets_total",
+			"Number of packets dropped on transmission on the interface",
+			[]string{"vdom", "name", "alias", "parent"}, nil,
+		)
+		mRxDrop = prometheus.NewDesc(
+			"fortigate_interface_receive_dropped_packets_total",
+			"Number of packets dropped on reception on the interface",
+			[]string{"vdom", "name", "alias", "parent"}, nil,
+		)
+		mTxMcast = prometheus.NewDesc(
+			"fortigate_interface_transmit_multicast_packets_total",
+			"Number of multicast packets transmitted on the interface",
+			[]string{"vdom", "name", "alias", "parent"}, nil,
+		)
+		mRxMcast = prometheus.NewDesc(
+			"fortigate_interface_receive_multicast_packets_total",
+			"Number of multicast packets received on the interface",
+			[]string{"vdom", "name", "alias", "parent"}, nil,
+		)
+		mTxBcast = prometheus.NewDesc(
+			"fortigate_interface_transmit_broadcast_packets_total",
+			"Number of broadcast packets transmitted on the interface",
+			[]string{"vdom", "name", "alias", "parent"}, nil,
+		)
+		mRxBcast = prometheus.NewDesc(
+			"fortigate_interface_receive_broadcast_packets_total",
+			"Number of broadcast packets received on the interface",
+			[]string{"vdom", "name", "alias", "parent"}, nil,
+		)
+		mTxBw = prometheus.NewDesc(
+			"fortigate_interface_transmit_bandwidth_bps",
+			"Current transmit bandwidth of the interface as computed by FortiOS in bits/s",
+			[]string{"vdom", "name", "alias", "parent"}, nil,
+		)
+		mRxBw = prometheus.NewDesc(
+			"fortigate_interface_receive_bandwidth_bps",
+			"Current receive bandwidth of the interface as computed by FortiOS in bits/s",
+			[]string{"vdom", "name", "alias", "parent"}, nil,
+		)
+		mInfo = prometheus.NewDesc(
+			"fortigate_interface_info",
+			"Configuration of the interface",
+			[]string{"vdom", "name", "alias", "parent", "role", "type", "vlanid", "mode"}, nil,
+		)
+		mAdmin = prometheus.NewDesc(
+			"fortigate_interface_admin_up",
+			"Whether the interface is administratively up or not",
+			[]string{"vdom", "name", "alias", "parent"}, nil,
+		)
+		mMTU = prometheus.NewDesc(
+			"fortigate_interface_mtu_bytes",
+			"MTU configured on the interface",
+			[]string{"vdom", "name", "alias", "parent"}, nil,
+		)
 	)
 
 	type ifResult struct {
@@ -78,6 +138,15 @@ func probeSystemInterface(c http.FortiHTTP, meta *TargetMetadata) ([]prometheus.
 		TxErrors  float64 `json:"tx_errors"`
 		RxErrors  float64 `json:"rx_errors"`
 		Interface string
+		// Only reported by some FortiOS versions
+		TxDropped   *float64 `json:"tx_dropped"`
+		RxDropped   *float64 `json:"rx_dropped"`
+		TxMulticast *float64 `json:"tx_multicast"`
+		RxMulticast *float64 `json:"rx_multicast"`
+		TxBroadcast *float64 `json:"tx_broadcast"`
+		RxBroadcast *float64 `json:"rx_broadcast"`
+		TxBandwidth *float64 `json:"tx_bandwidth"`
+		RxBandwidth *float64 `json:"rx_bandwidth"`
 	}
 	type ifResponse struct {
 		Results map[string]ifResult
@@ -86,12 +155,38 @@ func probeSystemInterface(c http.FortiHTTP, meta *TargetMetadata) ([]prometheus.
 	var r []ifResponse
 
 	if err := c.Get("api/v2/monitor/system/interface/select", "vdom=*&include_vlan=true&include_aggregate=true", &r); err != nil {
-		log.Printf("Error: %v", err)
+		meta.Logger().Error("API request failed", "err", err)
 		return nil, false
 	}
+
+	type ifConfig struct {
+		Name   string
+		Status string
+		Type   string
+		Role   string
+		VlanID int
+		Mode   string
+		MTU    float64
+	}
+	type ifConfigResponse struct {
+		Results []ifConfig
+	}
+	var rc ifConfigResponse
+
+	// The configuration needs another permission, without it only the info,
+	// admin and MTU metrics are left out
+	if err := c.Get("api/v2/cmdb/system/interface", "", &rc); err != nil {
+		meta.Logger().Warn("Failed to get interface configuration", "err", err)
+	}
+	configs := map[string]ifConfig{}
+	for _, ic := range rc.Results {
+		configs[ic.Name] = ic
+	}
+
 	m := []prometheus.Metric{}
 	for _, v := range r {
 		for _, ir := range v.Results {
+			labels := []string{v.VDOM, ir.Name, ir.Alias, ir.Interface}
 			linkf := 0.0
 			if ir.Link {
 				linkf = 1.0
@@ -104,6 +199,48 @@ func probeSystemInterface(c http.FortiHTTP, meta *TargetMetadata) ([]prometheus.
 			m = append(m, prometheus.MustNewConstMetric(mRxB, prometheus.CounterValue, ir.RxBytes, v.VDOM, ir.Name, ir.Alias, ir.Interface))
 			m = append(m, prometheus.MustNewConstMetric(mTxErr, prometheus.CounterValue, ir.TxErrors, v.VDOM, ir.Name, ir.Alias, ir.Interface))
 			m = append(m, prometheus.MustNewConstMetric(mRxErr, prometheus.CounterValue, ir.RxErrors, v.VDOM, ir.Name, ir.Alias, ir.Interface))
+			fullDuplex := 0.0
+			if ir.Duplex == 1 {
+				fullDuplex = 1.0
+			}
+			m = append(m, prometheus.MustNewConstMetric(mDuplex, prometheus.GaugeValue, fullDuplex, labels...))
+
+			for _, opt := range []struct {
+				desc *prometheus.Desc
+				t    prometheus.ValueType
+				v    *float64
+			}{
+				{mTxDrop, prometheus.CounterValue, ir.TxDropped},
+				{mRxDrop, prometheus.CounterValue, ir.RxDropped},
+				{mTxMcast, prometheus.CounterValue, ir.TxMulticast},
+				{mRxMcast, prometheus.CounterValue, ir.RxMulticast},
+				{mTxBcast, prometheus.CounterValue, ir.TxBroadcast},
+				{mRxBcast, prometheus.CounterValue, ir.RxBroadcast},
+				{mTxBw, prometheus.GaugeValue, ir.TxBandwidth},
+				{mRxBw, prometheus.GaugeValue, ir.RxBandwidth},
+			} {
+				if opt.v != nil {
+					m = append(m, prometheus.MustNewConstMetric(opt.desc, opt.t, *opt.v, labels...))
+				}
+			}
+
+			ic, found := configs[ir.Name]
+			if !found {
+				continue
+			}
+			vlanID := ""
+			if ic.VlanID != 0 {
+				vlanID = strconv.Itoa(ic.VlanID)
+			}
+			adminUp := 0.0
+			if ic.Status == "up" {
+				adminUp = 1.0
+			}
+			m = append(m, prometheus.MustNewConstMetric(mInfo, prometheus.GaugeValue, 1, v.VDOM, ir.Name, ir.Alias, ir.Interface, ic.Role, ic.Type, vlanID, ic.Mode))
+			m = append(m, prometheus.MustNewConstMetric(mAdmin, prometheus.GaugeValue, adminUp, labels...))
+			if ic.MTU > 0 {
+				m = append(m, prometheus.MustNewConstMetric(mMTU, prometheus.GaugeValue, ic.MTU, labels...))
+			}
 		}
 	}
 	return m, true
@@ -14,6 +14,7 @@
 package probe
 
 import (
+	"errors"
 	"strings"
 	"testing"
 
@@ -24,12 +25,67 @@ import (
 func TestSystemInterfaces(t *testing.T) {
 	c := newFakeClient()
 	c.prepare("api/v2/monitor/system/interface/select", "testdata/interface.jsonnet")
+	c.prepare("api/v2/cmdb/system/interface", "testdata/interface-config.jsonnet")
 	r := prometheus.NewPedanticRegistry()
 	if !testProbe(probeSystemInterface, c, r) {
 		t.Errorf("probeSystemInterface() returned non-success")
 	}
 
 	em := `
+	# HELP fortigate_interface_admin_up Whether the interface is administratively up or not
+	# TYPE fortigate_interface_admin_up gauge
+	fortigate_interface_admin_up{alias="",name="b",parent="",vdom="root"} 0
+	fortigate_interface_admin_up{alias="",name="internal1",parent="",vdom="infra"} 1
+	fortigate_interface_admin_up{alias="",name="internal2",parent="",vdom="infra"} 1
+	fortigate_interface_admin_up{alias="",name="internal3",parent="",vdom="root"} 0
+	fortigate_interface_admin_up{alias="",name="internal4",parent="",vdom="root"} 1
+	fortigate_interface_admin_up{alias="",name="internal5",parent="",vdom="root"} 1
+	fortigate_interface_admin_up{alias="",name="modem",parent="",vdom="root"} 0
+	fortigate_interface_admin_up{alias="",name="npu0_vlink0",parent="",vdom="root"} 1
+	fortigate_interface_admin_up{alias="",name="npu0_vlink1",parent="",vdom="root"} 1
+	fortigate_interface_admin_up{alias="",name="vlan-knx",parent="downlink",vdom="knx"} 1
+	fortigate_interface_admin_up{alias="",name="vlan-ocp-knx",parent="a",vdom="knx"} 1
+	fortigate_interface_admin_up{alias="",name="wan1",parent="",vdom="main"} 1
+	fortigate_interface_admin_up{alias="",name="wan2",parent="",vdom="root"} 0
+	fortigate_interface_admin_up{alias="(mgmt)",name="dmz",parent="",vdom="root"} 1
+	fortigate_interface_admin_up{alias="(ocp-mgmt)",name="a",parent="",vdom="main"} 1
+	fortigate_interface_admin_up{alias="(vlan-infra-mgmt)",name="downlink",parent="",vdom="infra"} 1
+	# HELP fortigate_interface_full_duplex Whether the link negotiated full duplex or not
+	# TYPE fortigate_interface_full_duplex gauge
+	fortigate_interface_full_duplex{alias="",name="b",parent="",vdom="root"} 0
+	fortigate_interface_full_duplex{alias="",name="internal1",parent="",vdom="infra"} 1
+	fortigate_interface_full_duplex{alias="",name="internal2",parent="",vdom="infra"} 1
+	fortigate_interface_full_duplex{alias="",name="internal3",parent="",vdom="root"} 0
+	fortigate_interface_full_duplex{alias="",name="internal4",parent="",vdom="root"} 0
+	fortigate_interface_full_duplex{alias="",name="internal5",parent="",vdom="root"} 0
+	fortigate_interface_full_duplex{alias="",name="modem",parent="",vdom="root"} 0
+	fortigate_interface_full_duplex{alias="",name="npu0_vlink0",parent="",vdom="root"} 1
+	fortigate_interface_full_duplex{alias="",name="npu0_vlink1",parent="",vdom="root"} 1
+	fortigate_interface_full_duplex{alias="",name="vlan-knx",parent="downlink",vdom="knx"} 1
+	fortigate_interface_full_duplex{alias="",name="vlan-ocp-knx",parent="a",vdom="knx"} 1
+	fortigate_interface_full_duplex{alias="",name="wan1",parent="",vdom="main"} 1
+	fortigate_interface_full_duplex{alias="",name="wan2",parent="",vdom="root"} 0
+	fortigate_interface_full_duplex{alias="(mgmt)",name="dmz",parent="",vdom="root"} 1
+	fortigate_interface_full_duplex{alias="(ocp-mgmt)",name="a",parent="",vdom="main"} 1
+	fortigate_interface_full_duplex{alias="(vlan-infra-mgmt)",name="downlink",parent="",vdom="infra"} 1
+	# HELP fortigate_interface_info Configuration of the interface
+	# TYPE fortigate_interface_info gauge
+	fortigate_interface_info{alias="",mode="pppoe",name="modem",parent="",role="wan",type="physical",vdom="root",vlanid=""} 1
+	fortigate_interface_info{alias="",mode="pppoe",name="wan2",parent="",role="wan",type="physical",vdom="root",vlanid=""} 1
+	fortigate_interface_info{alias="",mode="static",name="b",parent="",role="undefined",type="physical",vdom="root",vlanid=""} 1
+	fortigate_interface_info{alias="",mode="static",name="internal3",parent="",role="lan",type="physical",vdom="root",vlanid=""} 1
+	fortigate_interface_info{alias="",mode="dhcp",name="wan1",parent="",role="wan",type="physical",vdom="main",vlanid=""} 1
+	fortigate_interface_info{alias="",mode="static",name="internal1",parent="",role="lan",type="physical",vdom="infra",vlanid=""} 1
+	fortigate_interface_info{alias="",mode="static",name="internal2",parent="",role="lan",type="physical",vdom="infra",vlanid=""} 1
+	fortigate_interface_info{alias="",mode="static",name="internal4",parent="",role="lan",type="physical",vdom="root",vlanid=""} 1
+	fortigate_interface_info{alias="",mode="static",name="internal5",parent="",role="lan",type="physical",vdom="root",vlanid=""} 1
+	fortigate_interface_info{alias="",mode="static",name="npu0_vlink0",parent="",role="undefined",type="physical",vdom="root",vlanid=""} 1
+	fortigate_interface_info{alias="",mode="static",name="npu0_vlink1",parent="",role="undefined",type="physical",vdom="root",vlanid=""} 1
+	fortigate_interface_info{alias="",mode="static",name="vlan-knx",parent="downlink",role="lan",type="vlan",vdom="knx",vlanid="20"} 1
+	fortigate_interface_info{alias="",mode="static",name="vlan-ocp-knx",parent="a",role="dmz",type="vlan",vdom="knx",vlanid="21"} 1
+	fortigate_interface_info{alias="(mgmt)",mode="static",name="dmz",parent="",role="dmz",type="physical",vdom="root",vlanid=""} 1
+	fortigate_interface_info{alias="(ocp-mgmt)",mode="static",name="a",parent="",role="lan",type="physical",vdom="main",vlanid=""} 1
+	fortigate_interface_info{alias="(vlan-infra-mgmt)",mode="static",name="downlink",parent="",role="lan",type="aggregate",vdom="infra",vlanid=""} 1
 	# HELP fortigate_interface_link_up Whether the link is up or not (not taking into account admin status)
 	# TYPE fortigate_interface_link_up gauge
 	fortigate_interface_link_up{alias="",name="b",parent="",vdom="root"} 0
@@ -48,6 +104,30 @@ func TestSystemInterfaces(t *testing.T) {
 	fortigate_interface_link_up{alias="(mgmt)",name="dmz",parent="",vdom="root"} 1
 	fortigate_interface_link_up{alias="(ocp-mgmt)",name="a",parent="",vdom="main"} 1
 	fortigate_interface_link_up{alias="(vlan-infra-mgmt)",name="downlink",parent="",vdom="infra"} 1
+	# HELP fortigate_interface_mtu_bytes MTU configured on the interface
+	# TYPE fortigate_interface_mtu_bytes gauge
+	fortigate_interface_mtu_bytes{alias="",name="b",parent="",vdom="root"} 1500
+	fortigate_interface_mtu_bytes{alias="",name="internal1",parent="",vdom="infra"} 1500
+	fortigate_interface_mtu_bytes{alias="",name="internal2",parent="",vdom="infra"} 1500
+	fortigate_interface_mtu_bytes{alias="",name="internal3",parent="",vdom="root"} 1500
+	fortigate_interface_mtu_bytes{alias="",name="internal4",parent="",vdom="root"} 1500
+	fortigate_interface_mtu_bytes{alias="",name="internal5",parent="",vdom="root"} 1500
+	fortigate_interface_mtu_bytes{alias="",name="modem",parent="",vdom="root"} 1500
+	fortigate_interface_mtu_bytes{alias="",name="npu0_vlink0",parent="",vdom="root"} 1500
+	fortigate_interface_mtu_bytes{alias="",name="npu0_vlink1",parent="",vdom="root"} 1500
+	fortigate_interface_mtu_bytes{alias="",name="vlan-knx",parent="downlink",vdom="knx"} 1500
+	fortigate_interface_mtu_bytes{alias="",name="vlan-ocp-knx",parent="a",vdom="knx"} 1500
+	fortigate_interface_mtu_bytes{alias="",name="wan1",parent="",vdom="main"} 1500
+	fortigate_interface_mtu_bytes{alias="",name="wan2",parent="",vdom="root"} 1492
+	fortigate_interface_mtu_bytes{alias="(mgmt)",name="dmz",parent="",vdom="root"} 1500
+	fortigate_interface_mtu_bytes{alias="(ocp-mgmt)",name="a",parent="",vdom="main"} 1500
+	fortigate_interface_mtu_bytes{alias="(vlan-infra-mgmt)",name="downlink",parent="",vdom="infra"} 9000
+	# HELP fortigate_interface_receive_bandwidth_bps Current receive bandwidth of the interface as computed by FortiOS in bits/s
+	# TYPE fortigate_interface_receive_bandwidth_bps gauge
+	fortigate_interface_receive_bandwidth_bps{alias="",name="wan1",parent="",vdom="main"} 1.8734112e+07
+	# HELP fortigate_interface_receive_broadcast_packets_total Number of broadcast packets received on the interface
+	# TYPE fortigate_interface_receive_broadcast_packets_total counter
+	fortigate_interface_receive_broadcast_packets_total{alias="",name="wan1",parent="",vdom="main"} 98214
 	# HELP fortigate_interface_receive_bytes_total Number of bytes received on the interface
 	# TYPE fortigate_interface_receive_bytes_total counter
 	fortigate_interface_receive_bytes_total{alias="",name="b",parent="",vdom="root"} 0
@@ -66,6 +146,9 @@ func TestSystemInterfaces(t *testing.T) {
 	fortigate_interface_receive_bytes_total{alias="(mgmt)",name="dmz",parent="",vdom="root"} 1.310564319e+09
 	fortigate_interface_receive_bytes_total{alias="(ocp-mgmt)",name="a",parent="",vdom="main"} 1.4568944108e+10
 	fortigate_interface_receive_bytes_total{alias="(vlan-infra-mgmt)",name="downlink",parent="",vdom="infra"} 2.5353784011e+10
+	# HELP fortigate_interface_receive_dropped_packets_total Number of packets dropped on reception on the interface
+	# TYPE fortigate_interface_receive_dropped_packets_total counter
+	fortigate_interface_receive_dropped_packets_total{alias="",name="wan1",parent="",vdom="main"} 3481
 	# HELP fortigate_interface_receive_errors_total Number of reception errors detected on the interface
 	# TYPE fortigate_interface_receive_errors_total counter
 	fortigate_interface_receive_errors_total{alias="",name="b",parent="",vdom="root"} 0
@@ -84,6 +167,9 @@ func TestSystemInterfaces(t *testing.T) {
 	fortigate_interface_receive_errors_total{alias="(mgmt)",name="dmz",parent="",vdom="root"} 0
 	fortigate_interface_receive_errors_total{alias="(ocp-mgmt)",name="a",parent="",vdom="main"} 0
 	fortigate_interface_receive_errors_total{alias="(vlan-infra-mgmt)",name="downlink",parent="",vdom="infra"} 0
+	# HELP fortigate_interface_receive_multicast_packets_total Number of multicast packets received on the interface
+	# TYPE fortigate_interface_receive_multicast_packets_total counter
+	fortigate_interface_receive_multicast_packets_total{alias="",name="wan1",parent="",vdom="main"} 21873
 	# HELP fortigate_interface_receive_packets_total Number of packets received on the interface
 	# TYPE fortigate_interface_receive_packets_total counter
 	fortigate_interface_receive_packets_total{alias="",name="b",parent="",vdom="root"} 0
@@ -120,6 +206,12 @@ func TestSystemInterfaces(t *testing.T) {
 	fortigate_interface_speed_bps{alias="(mgmt)",name="dmz",parent="",vdom="root"} 1e+09
 	fortigate_interface_speed_bps{alias="(ocp-mgmt)",name="a",parent="",vdom="main"} 1e+09
 	fortigate_interface_speed_bps{alias="(vlan-infra-mgmt)",name="downlink",parent="",vdom="infra"} 2e+09
+	# HELP fortigate_interface_transmit_bandwidth_bps Current transmit bandwidth of the interface as computed by FortiOS in bits/s
+	# TYPE fortigate_interface_transmit_bandwidth_bps gauge
+	fortigate_interface_transmit_bandwidth_bps{alias="",name="wan1",parent="",vdom="main"} 2.584312e+06
+	# HELP fortigate_interface_transmit_broadcast_packets_total Number of broadcast packets transmitted on the interface
+	# TYPE fortigate_interface_transmit_broadcast_packets_total counter
+	fortigate_interface_transmit_broadcast_packets_total{alias="",name="wan1",parent="",vdom="main"} 4
 	# HELP fortigate_interface_transmit_bytes_total Number of bytes transmitted on the interface
 	# TYPE fortigate_interface_transmit_bytes_total counter
 	fortigate_interface_transmit_bytes_total{alias="",name="b",parent="",vdom="root"} 0
@@ -138,6 +230,9 @@ func TestSystemInterfaces(t *testing.T) {
 	fortigate_interface_transmit_bytes_total{alias="(mgmt)",name="dmz",parent="",vdom="root"} 2.489018103e+09
 	fortigate_interface_transmit_bytes_total{alias="(ocp-mgmt)",name="a",parent="",vdom="main"} 5.5827906482e+10
 	fortigate_interface_transmit_bytes_total{alias="(vlan-infra-mgmt)",name="downlink",parent="",vdom="infra"} 2.3561313347e+10
+	# HELP fortigate_interface_transmit_dropped_packets_total Number of packets dropped on transmission on the interface
+	# TYPE fortigate_interface_transmit_dropped_packets_total counter
+	fortigate_interface_transmit_dropped_packets_total{alias="",name="wan1",parent="",vdom="main"} 12
 	# HELP fortigate_interface_transmit_errors_total Number of transmission errors detected on the interface
 	# TYPE fortigate_interface_transmit_errors_total counter
 	fortigate_interface_transmit_errors_total{alias="",name="b",parent="",vdom="root"} 0
@@ -156,6 +251,9 @@ func TestSystemInterfaces(t *testing.T) {
 	fortigate_interface_transmit_errors_total{alias="(mgmt)",name="dmz",parent="",vdom="root"} 0
 	fortigate_interface_transmit_errors_total{alias="(ocp-mgmt)",name="a",parent="",vdom="main"} 0
 	fortigate_interface_transmit_errors_total{alias="(vlan-infra-mgmt)",name="downlink",parent="",vdom="infra"} 0
+	# HELP fortigate_interface_transmit_multicast_packets_total Number of multicast packets transmitted on the interface
+	# TYPE fortigate_interface_transmit_multicast_packets_total counter
+	fortigate_interface_transmit_multicast_packets_total{alias="",name="wan1",parent="",vdom="main"} 0
 	# HELP fortigate_interface_transmit_packets_total Number of packets transmitted on the interface
 	# TYPE fortigate_interface_transmit_packets_total counter
 	fortigate_interface_transmit_packets_total{alias="",name="b",parent="",vdom="root"} 0
@@ -179,3 +277,17 @@ func TestSystemInterfaces(t *testing.T) {
 		t.Fatalf("metric compare: err %v", err)
 	}
 }
+
+func TestSystemInterfacesNoConfigAccess(t *testing.T) {
+	c := newFakeClient()
+	c.prepare("api/v2/monitor/system/interface/select", "testdata/interface.jsonnet")
+	c.prepareError("api/v2/cmdb/system/interface", errors.New("permission denied"))
+	m, ok := probeSystemInterface(c, &TargetMetadata{})
+	if !ok {
+		t.Errorf("probeSystemInterface() returned non-success without interface config access")
+	}
+	// 9 metrics for each of the 16 interfaces and the 8 optional counters of wan1
+	if len(m) != 9*16+8 {
+		t.Errorf("probeSystemInterface() returned %d metrics, want %d", len(m), 9*16+8)
+	}
+}
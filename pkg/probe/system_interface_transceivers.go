@@ -0,0 +1,111 @@
+// Copyright 2025 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package probe
+
+import (
+	"github.com/prometheus-community/fortigate_exporter/pkg/http"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// TransceiverReading is a digital optical monitoring value with its
+// thresholds, which some modules do not report
+type TransceiverReading struct {
+	Value       float64  `json:"value"`
+	HighAlarm   *float64 `json:"high_alarm"`
+	LowAlarm    *float64 `json:"low_alarm"`
+	HighWarning *float64 `json:"high_warning"`
+	LowWarning  *float64 `json:"low_warning"`
+}
+
+type Transceiver struct {
+	Name         string              `json:"name"`
+	Type         string              `json:"type"`
+	Vendor       string              `json:"vendor"`
+	PartNumber   string              `json:"vendor_part_number"`
+	SerialNumber string              `json:"vendor_serial_number"`
+	Temperature  *TransceiverReading `json:"temperature"`
+	Voltage      *TransceiverReading `json:"voltage"`
+	TxBias       *TransceiverReading `json:"tx_bias"`
+	TxPower      *TransceiverReading `json:"tx_power"`
+	RxPower      *TransceiverReading `json:"rx_power"`
+}
+
+type TransceiverResponse struct {
+	Results []Transceiver `json:"results"`
+}
+
+func probeSystemInterfaceTransceivers(c http.FortiHTTP, meta *TargetMetadata) ([]prometheus.Metric, bool) {
+	if meta.VersionMajor < 7 {
+		// not supported version. Before 7.0.0 the requested endpoint doesn't exist
+		return nil, true
+	}
+	var (
+		mInfo = prometheus.NewDesc(
+			"fortigate_interface_transceiver_info",
+			"Transceiver module plugged into the interface",
+			[]string{"name", "type", "vendor", "part_number", "serial"}, nil,
+		)
+	)
+
+	// readings are the digital optical monitoring values, converted to base units
+	readings := []struct {
+		name  string
+		unit  string
+		help  string
+		scale float64
+		get   func(t *Transceiver) *TransceiverReading
+	}{
+		{"temperature", "celsius", "Temperature of the transceiver", 1, func(t *Transceiver) *TransceiverReading { return t.Temperature }},
+		{"voltage", "volts", "Supply voltage of the transceiver", 1, func(t *Transceiver) *TransceiverReading { return t.Voltage }},
+		// FortiOS returns the bias current in mA
+		{"tx_bias", "amperes", "Laser bias current of the transceiver", 0.001, func(t *Transceiver) *TransceiverReading { return t.TxBias }},
+		{"tx_power", "dbm", "Optical transmit power of the transceiver", 1, func(t *Transceiver) *TransceiverReading { return t.TxPower }},
+		{"rx_power", "dbm", "Optical receive power of the transceiver", 1, func(t *Transceiver) *TransceiverReading { return t.RxPower }},
+	}
+
+	var res TransceiverResponse
+	if err := c.Get("api/v2/monitor/system/interface/transceivers", "scope=global", &res); err != nil {
+		meta.Logger().Error("API request failed", "err", err)
+		return nil, false
+	}
+
+	m := []prometheus.Metric{}
+	for i := range res.Results {
+		t := &res.Results[i]
+		m = append(m, prometheus.MustNewConstMetric(mInfo, prometheus.GaugeValue, 1, t.Name, t.Type, t.Vendor, t.PartNumber, t.SerialNumber))
+		for _, r := range readings {
+			v := r.get(t)
+			if v == nil {
+				// Modules without digital optical monitoring, e.g. copper SFPs
+				continue
+			}
+			value := prometheus.NewDesc("fortigate_interface_transceiver_"+r.name+"_"+r.unit, r.help, []string{"name"}, nil)
+			threshold := prometheus.NewDesc("fortigate_interface_transceiver_"+r.name+"_threshold_"+r.unit, r.help+", alarm and warning thresholds", []string{"name", "level"}, nil)
+			m = append(m, prometheus.MustNewConstMetric(value, prometheus.GaugeValue, v.Value*r.scale, t.Name))
+			for level, tv := range map[string]*float64{
+				"high_alarm":   v.HighAlarm,
+				"low_alarm":    v.LowAlarm,
+				"high_warning": v.HighWarning,
+				"low_warning":  v.LowWarning,
+			} {
+				if tv == nil {
+					continue
+				}
+				m = append(m, prometheus.MustNewConstMetric(threshold, prometheus.GaugeValue, *tv*r.scale, t.Name, level))
+			}
+		}
+	}
+
+	return m, true
+}
@@ -0,0 +1,117 @@
+// Copyright 2025 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package probe
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestSystemInterfaceTransceivers(t *testing.T) {
+	c := newFakeClient()
+	c.prepare("api/v2/monitor/system/interface/transceivers", "testdata/interface-transceivers.jsonnet")
+	r := prometheus.NewPedanticRegistry()
+	if !testProbe(probeSystemInterfaceTransceivers, c, r) {
+		t.Errorf("probeSystemInterfaceTransceivers() returned non-success")
+	}
+
+	em := `
+	# HELP fortigate_interface_transceiver_info Transceiver module plugged into the interface
+	# TYPE fortigate_interface_transceiver_info gauge
+	fortigate_interface_transceiver_info{name="port1",part_number="FN-TRAN-GC",serial="FNS0F1G2H3I4J",type="SFP/SFP+/SFP28",vendor="FORTINET"} 1
+	fortigate_interface_transceiver_info{name="port37",part_number="FN-TRAN-SFP28-SR",serial="FNS0A0B0C0D0E",type="SFP/SFP+/SFP28",vendor="FORTINET"} 1
+	fortigate_interface_transceiver_info{name="port38",part_number="FTLX8574D3BCL",serial="AQK1B2C",type="SFP/SFP+/SFP28",vendor="FINISAR CORP."} 1
+	fortigate_interface_transceiver_info{name="port39",part_number="SFP-10G-LR",serial="OEM0123456",type="SFP/SFP+/SFP28",vendor="OEM"} 1
+	# HELP fortigate_interface_transceiver_rx_power_dbm Optical receive power of the transceiver
+	# TYPE fortigate_interface_transceiver_rx_power_dbm gauge
+	fortigate_interface_transceiver_rx_power_dbm{name="port37"} -14.81
+	fortigate_interface_transceiver_rx_power_dbm{name="port38"} -2.45
+	fortigate_interface_transceiver_rx_power_dbm{name="port39"} -3.1
+	# HELP fortigate_interface_transceiver_rx_power_threshold_dbm Optical receive power of the transceiver, alarm and warning thresholds
+	# TYPE fortigate_interface_transceiver_rx_power_threshold_dbm gauge
+	fortigate_interface_transceiver_rx_power_threshold_dbm{level="high_alarm",name="port37"} 4
+	fortigate_interface_transceiver_rx_power_threshold_dbm{level="high_alarm",name="port38"} 4
+	fortigate_interface_transceiver_rx_power_threshold_dbm{level="high_alarm",name="port39"} 3
+	fortigate_interface_transceiver_rx_power_threshold_dbm{level="high_warning",name="port37"} 2
+	fortigate_interface_transceiver_rx_power_threshold_dbm{level="high_warning",name="port38"} 2
+	fortigate_interface_transceiver_rx_power_threshold_dbm{level="low_alarm",name="port37"} -10.3
+	fortigate_interface_transceiver_rx_power_threshold_dbm{level="low_alarm",name="port38"} -10.3
+	fortigate_interface_transceiver_rx_power_threshold_dbm{level="low_alarm",name="port39"} -12
+	fortigate_interface_transceiver_rx_power_threshold_dbm{level="low_warning",name="port37"} -8.3
+	fortigate_interface_transceiver_rx_power_threshold_dbm{level="low_warning",name="port38"} -8.3
+	# HELP fortigate_interface_transceiver_temperature_celsius Temperature of the transceiver
+	# TYPE fortigate_interface_transceiver_temperature_celsius gauge
+	fortigate_interface_transceiver_temperature_celsius{name="port37"} 34.2
+	fortigate_interface_transceiver_temperature_celsius{name="port38"} 29.8
+	fortigate_interface_transceiver_temperature_celsius{name="port39"} 41.5
+	# HELP fortigate_interface_transceiver_temperature_threshold_celsius Temperature of the transceiver, alarm and warning thresholds
+	# TYPE fortigate_interface_transceiver_temperature_threshold_celsius gauge
+	fortigate_interface_transceiver_temperature_threshold_celsius{level="high_alarm",name="port37"} 78
+	fortigate_interface_transceiver_temperature_threshold_celsius{level="high_alarm",name="port38"} 78
+	fortigate_interface_transceiver_temperature_threshold_celsius{level="high_warning",name="port37"} 73
+	fortigate_interface_transceiver_temperature_threshold_celsius{level="high_warning",name="port38"} 73
+	fortigate_interface_transceiver_temperature_threshold_celsius{level="low_alarm",name="port37"} -13
+	fortigate_interface_transceiver_temperature_threshold_celsius{level="low_alarm",name="port38"} -13
+	fortigate_interface_transceiver_temperature_threshold_celsius{level="low_warning",name="port37"} -8
+	fortigate_interface_transceiver_temperature_threshold_celsius{level="low_warning",name="port38"} -8
+	# HELP fortigate_interface_transceiver_tx_bias_amperes Laser bias current of the transceiver
+	# TYPE fortigate_interface_transceiver_tx_bias_amperes gauge
+	fortigate_interface_transceiver_tx_bias_amperes{name="port37"} 0.00741
+	fortigate_interface_transceiver_tx_bias_amperes{name="port38"} 0.006900000000000001
+	# HELP fortigate_interface_transceiver_tx_bias_threshold_amperes Laser bias current of the transceiver, alarm and warning thresholds
+	# TYPE fortigate_interface_transceiver_tx_bias_threshold_amperes gauge
+	fortigate_interface_transceiver_tx_bias_threshold_amperes{level="high_alarm",name="port37"} 0.013000000000000001
+	fortigate_interface_transceiver_tx_bias_threshold_amperes{level="high_alarm",name="port38"} 0.013000000000000001
+	fortigate_interface_transceiver_tx_bias_threshold_amperes{level="high_warning",name="port37"} 0.0125
+	fortigate_interface_transceiver_tx_bias_threshold_amperes{level="high_warning",name="port38"} 0.0125
+	fortigate_interface_transceiver_tx_bias_threshold_amperes{level="low_alarm",name="port37"} 0.004
+	fortigate_interface_transceiver_tx_bias_threshold_amperes{level="low_alarm",name="port38"} 0.004
+	fortigate_interface_transceiver_tx_bias_threshold_amperes{level="low_warning",name="port37"} 0.005
+	fortigate_interface_transceiver_tx_bias_threshold_amperes{level="low_warning",name="port38"} 0.005
+	# HELP fortigate_interface_transceiver_tx_power_dbm Optical transmit power of the transceiver
+	# TYPE fortigate_interface_transceiver_tx_power_dbm gauge
+	fortigate_interface_transceiver_tx_power_dbm{name="port37"} -1.22
+	fortigate_interface_transceiver_tx_power_dbm{name="port38"} -2.01
+	# HELP fortigate_interface_transceiver_tx_power_threshold_dbm Optical transmit power of the transceiver, alarm and warning thresholds
+	# TYPE fortigate_interface_transceiver_tx_power_threshold_dbm gauge
+	fortigate_interface_transceiver_tx_power_threshold_dbm{level="high_alarm",name="port37"} 4
+	fortigate_interface_transceiver_tx_power_threshold_dbm{level="high_alarm",name="port38"} 4
+	fortigate_interface_transceiver_tx_power_threshold_dbm{level="high_warning",name="port37"} 2
+	fortigate_interface_transceiver_tx_power_threshold_dbm{level="high_warning",name="port38"} 2
+	fortigate_interface_transceiver_tx_power_threshold_dbm{level="low_alarm",name="port37"} -8.4
+	fortigate_interface_transceiver_tx_power_threshold_dbm{level="low_alarm",name="port38"} -8.4
+	fortigate_interface_transceiver_tx_power_threshold_dbm{level="low_warning",name="port37"} -6.4
+	fortigate_interface_transceiver_tx_power_threshold_dbm{level="low_warning",name="port38"} -6.4
+	# HELP fortigate_interface_transceiver_voltage_threshold_volts Supply voltage of the transceiver, alarm and warning thresholds
+	# TYPE fortigate_interface_transceiver_voltage_threshold_volts gauge
+	fortigate_interface_transceiver_voltage_threshold_volts{level="high_alarm",name="port37"} 3.7
+	fortigate_interface_transceiver_voltage_threshold_volts{level="high_alarm",name="port38"} 3.7
+	fortigate_interface_transceiver_voltage_threshold_volts{level="high_warning",name="port37"} 3.6
+	fortigate_interface_transceiver_voltage_threshold_volts{level="high_warning",name="port38"} 3.6
+	fortigate_interface_transceiver_voltage_threshold_volts{level="low_alarm",name="port37"} 2.9
+	fortigate_interface_transceiver_voltage_threshold_volts{level="low_alarm",name="port38"} 2.9
+	fortigate_interface_transceiver_voltage_threshold_volts{level="low_warning",name="port37"} 3
+	fortigate_interface_transceiver_voltage_threshold_volts{level="low_warning",name="port38"} 3
+	# HELP fortigate_interface_transceiver_voltage_volts Supply voltage of the transceiver
+	# TYPE fortigate_interface_transceiver_voltage_volts gauge
+	fortigate_interface_transceiver_voltage_volts{name="port37"} 3.29
+	fortigate_interface_transceiver_voltage_volts{name="port38"} 3.31
+	`
+	if err := testutil.GatherAndCompare(r, strings.NewReader(em)); err != nil {
+		t.Fatalf("metric compare: err %v", err)
+	}
+}
@@ -14,13 +14,156 @@
 package probe
 
 import (
+	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
+	"math"
+	"strings"
 
+	"github.com/prometheus-community/fortigate_exporter/internal/config"
 	"github.com/prometheus-community/fortigate_exporter/pkg/http"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+// resUsage is a resource type of system/resource/usage with its history over
+// the requested interval
+type resUsage struct {
+	Current    float64
+	Historical map[string]struct {
+		Values  [][2]float64 `json:"values"`
+		Min     *float64     `json:"min"`
+		Max     *float64     `json:"max"`
+		Average *float64     `json:"average"`
+	}
+}
+
+// window returns the minimum, maximum and average of the resource over
+// interval, as returned by FortiOS or calculated from the values if not
+func (r resUsage) window(interval string) (stats [3]float64, ok bool) {
+	h, ok := r.Historical[interval]
+	if !ok {
+		return stats, false
+	}
+	if h.Min != nil && h.Max != nil && h.Average != nil {
+		return [3]float64{*h.Min, *h.Max, *h.Average}, true
+	}
+	if len(h.Values) == 0 {
+		return stats, false
+	}
+	stats[0], stats[1] = math.Inf(1), math.Inf(-1)
+	for _, v := range h.Values {
+		stats[0] = math.Min(stats[0], v[1])
+		stats[1] = math.Max(stats[1], v[1])
+		stats[2] += v[1]
+	}
+	stats[2] /= float64(len(h.Values))
+	return stats, true
+}
+
+var windowStats = [3]string{"min", "max", "average"}
+
+// decodeResources decodes the resource types one by one, a type that fails to
+// decode is logged and left out instead of failing the whole probe
+func decodeResources(raw map[string]json.RawMessage, logger *slog.Logger) map[string][]resUsage {
+	results := make(map[string][]resUsage, len(raw))
+	for name, r := range raw {
+		var rs []resUsage
+		if err := json.Unmarshal(r, &rs); err != nil {
+			logger.Warn("Failed to decode resource type", "resource", name, "err", err)
+			continue
+		}
+		results[name] = rs
+	}
+	return results
+}
+
+// resourceType describes how a resource type of system/resource/usage is
+// exported, the global metric is prefixed by fortigate_, the per VDOM one by
+// fortigate_vdom_
+type resourceType struct {
+	name  string
+	help  string
+	label string
+	value string
+	scale float64
+	// dedicated types have their current value exported by the probe itself
+	dedicated bool
+}
+
+// resourceTypes maps the resource types to their metrics, unknown types are
+// exported as fortigate_resource_usage
+var resourceTypes = map[string]resourceType{
+	"cpu":                   {name: "cpu_usage_ratio", scale: 0.01, dedicated: true},
+	"mem":                   {name: "memory_usage_ratio", scale: 0.01, dedicated: true},
+	"session":               {name: "current_sessions", label: "protocol", value: "ipv4", scale: 1, dedicated: true},
+	"session6":              {name: "current_sessions", label: "protocol", value: "ipv6", scale: 1, dedicated: true},
+	"disk":                  {name: "disk_usage_ratio", help: "Current resource usage ratio of the disk", scale: 0.01},
+	"setuprate":             {name: "session_setup_rate", help: "Sessions set up per second, per IP version", label: "protocol", value: "ipv4", scale: 1},
+	"setuprate6":            {name: "session_setup_rate", help: "Sessions set up per second, per IP version", label: "protocol", value: "ipv6", scale: 1},
+	"npu_session":           {name: "npu_sessions", help: "Current amount of sessions offloaded to the NPU, per IP version", label: "protocol", value: "ipv4", scale: 1},
+	"npu_session6":          {name: "npu_sessions", help: "Current amount of sessions offloaded to the NPU, per IP version", label: "protocol", value: "ipv6", scale: 1},
+	"nturbo_session":        {name: "nturbo_sessions", help: "Current amount of sessions offloaded to nTurbo, per IP version", label: "protocol", value: "ipv4", scale: 1},
+	"nturbo_session6":       {name: "nturbo_sessions", help: "Current amount of sessions offloaded to nTurbo, per IP version", label: "protocol", value: "ipv6", scale: 1},
+	"disk_lograte":          {name: "log_rate", help: "Logs written per second, per destination", label: "destination", value: "disk", scale: 1},
+	"faz_lograte":           {name: "log_rate", help: "Logs written per second, per destination", label: "destination", value: "fortianalyzer", scale: 1},
+	"forticloud_lograte":    {name: "log_rate", help: "Logs written per second, per destination", label: "destination", value: "forticloud", scale: 1},
+	"gtp_tunnel":            {name: "gtp_tunnels", help: "Current amount of GTP tunnels", scale: 1},
+	"gtp_tunnel_setup_rate": {name: "gtp_tunnel_setup_rate", help: "GTP tunnels set up per second", scale: 1},
+}
+
+// resourceMetrics returns the current value of the resource types in results
+// that have no dedicated metric, and the window statistics of all of them
+func resourceMetrics(results map[string][]resUsage, prefix, interval string, labels []string, values ...string) []prometheus.Metric {
+	m := []prometheus.Metric{}
+	for name, rs := range results {
+		if len(rs) == 0 {
+			continue
+		}
+		rt, ok := resourceTypes[name]
+		if !ok {
+			rt = resourceType{
+				name:  "resource_usage",
+				help:  "Current value of a resource type without a dedicated metric, as returned by FortiOS",
+				label: "resource",
+				value: name,
+				scale: 1,
+			}
+		}
+		ls, vs := labels, values
+		if rt.label != "" {
+			ls = append(append([]string{}, labels...), rt.label)
+			vs = append(append([]string{}, values...), rt.value)
+		}
+		if !rt.dedicated {
+			help := rt.help
+			if len(labels) > 0 {
+				help += ", per VDOM"
+			}
+			desc := prometheus.NewDesc(prefix+rt.name, help, ls, nil)
+			m = append(m, prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, rs[0].Current*rt.scale, vs...))
+		}
+
+		stats, ok := rs[0].window(interval)
+		if !ok {
+			continue
+		}
+		// Keep the unit last, e.g. fortigate_memory_usage_window_ratio
+		window := rt.name + "_window"
+		if base, ok := strings.CutSuffix(rt.name, "_ratio"); ok {
+			window = base + "_window_ratio"
+		}
+		desc := prometheus.NewDesc(
+			prefix+window,
+			fmt.Sprintf("Minimum, maximum and average of %s over the resource usage interval", prefix+rt.name),
+			append(ls, "stat"), nil,
+		)
+		for i, s := range stats {
+			m = append(m, prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, s*rt.scale, append(vs, windowStats[i])...))
+		}
+	}
+	return m
+}
+
 func probeSystemResourceUsage(c http.FortiHTTP, meta *TargetMetadata) ([]prometheus.Metric, bool) {
 	var (
 		mResCPU = prometheus.NewDesc(
@@ -40,46 +183,38 @@ func probeSystemResourceUsage(c http.FortiHTTP, meta *TargetMetadata) ([]prometh
 		)
 	)
 
-	type resUsage struct {
-		Current float64
-	}
-	type resContainer struct {
-		CPU []resUsage
-		Mem []resUsage
-		// Ignore "disk", we get that from log/current-disk-usage instead with better resolution
-		Session  []resUsage
-		Session6 []resUsage
-		// TODO(bluecmd): These are TODO
-		// Setuprate []resUsage
-		// Setuprate6 []resUsage
-		// NpuSession []resUsage `json:"npu_session"`
-		// NpuSession6 []resUsage `json:"npu_session6"`
-		// NturboSession []resUsage  `json:"nturbo_session"`
-		// NturboSession6 []resUsage `json:"nturbo_session6"`
-		// DiskLograte []resUsage `json:"disk_lograte"`
-		// FazLograte []resUsage `json:"faz_lograte"`
-		// ForticloudLograte []resUsage `json:"forticloud_lograte"`
-	}
 	type systemResourceUsage struct {
-		Results resContainer
+		Results map[string]json.RawMessage
 		VDOM    string
 	}
 	var sr systemResourceUsage
 
-	if err := c.Get("api/v2/monitor/system/resource/usage", "interval=1-min&scope=global", &sr); err != nil {
-		log.Printf("Error: %v", err)
+	interval := config.GetConfig().ResInterval
+	if err := c.Get("api/v2/monitor/system/resource/usage", "interval="+interval+"&scope=global", &sr); err != nil {
+		meta.Logger().Error("API request failed", "err", err)
 		return nil, false
 	}
 
+	results := decodeResources(sr.Results, meta.Logger())
+
 	// CPU[0] is the average over all cores, ignore it
 	m := []prometheus.Metric{}
-	for i, cpu := range sr.Results.CPU[1:] {
-		m = append(m, prometheus.MustNewConstMetric(
-			mResCPU, prometheus.GaugeValue, float64(cpu.Current)/100.0, fmt.Sprintf("%d", i)))
+	if cpu := results["cpu"]; len(cpu) > 1 {
+		for i, c := range cpu[1:] {
+			m = append(m, prometheus.MustNewConstMetric(
+				mResCPU, prometheus.GaugeValue, float64(c.Current)/100.0, fmt.Sprintf("%d", i)))
+		}
+	}
+	if mem := results["mem"]; len(mem) > 0 {
+		m = append(m, prometheus.MustNewConstMetric(mResMemory, prometheus.GaugeValue, float64(mem[0].Current)/100.0))
 	}
-	m = append(m, prometheus.MustNewConstMetric(mResMemory, prometheus.GaugeValue, float64(sr.Results.Mem[0].Current)/100.0))
-	m = append(m, prometheus.MustNewConstMetric(mResSession, prometheus.GaugeValue, float64(sr.Results.Session[0].Current), "ipv4"))
-	m = append(m, prometheus.MustNewConstMetric(mResSession, prometheus.GaugeValue, float64(sr.Results.Session6[0].Current), "ipv6"))
+	if session := results["session"]; len(session) > 0 {
+		m = append(m, prometheus.MustNewConstMetric(mResSession, prometheus.GaugeValue, float64(session[0].Current), "ipv4"))
+	}
+	if session6 := results["session6"]; len(session6) > 0 {
+		m = append(m, prometheus.MustNewConstMetric(mResSession, prometheus.GaugeValue, float64(session6[0].Current), "ipv6"))
+	}
+	m = append(m, resourceMetrics(results, "fortigate_", interval, nil)...)
 	return m, true
 }
 
@@ -102,38 +237,33 @@ func probeSystemVDOMResources(c http.FortiHTTP, meta *TargetMetadata) ([]prometh
 		)
 	)
 
-	type resUsage struct {
-		Current float64
-	}
-	type resContainer struct {
-		CPU []resUsage
-		Mem []resUsage
-		// Ignore "disk", we get that from log/current-disk-usage instead with better resolution
-		Session  []resUsage
-		Session6 []resUsage
-		// TODO(bluecmd): These are TODO
-		// Setuprate []resUsage
-		// Setuprate6 []resUsage
-		// DiskLograte []resUsage `json:"disk_lograte"`
-		// FazLograte []resUsage `json:"faz_lograte"`
-		// ForticloudLograte []resUsage `json:"forticloud_lograte"`
-	}
 	type systemResourceUsage struct {
-		Results resContainer
+		Results map[string]json.RawMessage
 		VDOM    string
 	}
 	var sr []systemResourceUsage
 
-	if err := c.Get("api/v2/monitor/system/resource/usage", "interval=1-min&vdom=*", &sr); err != nil {
-		log.Printf("Error: %v", err)
+	interval := config.GetConfig().ResInterval
+	if err := c.Get("api/v2/monitor/system/resource/usage", "interval="+interval+"&vdom=*", &sr); err != nil {
+		meta.Logger().Error("API request failed", "err", err)
 		return nil, false
 	}
 	m := []prometheus.Metric{}
 	for _, s := range sr {
-		m = append(m, prometheus.MustNewConstMetric(mResCPU, prometheus.GaugeValue, float64(s.Results.CPU[0].Current)/100.0, s.VDOM))
-		m = append(m, prometheus.MustNewConstMetric(mResMemory, prometheus.GaugeValue, float64(s.Results.Mem[0].Current)/100.0, s.VDOM))
-		m = append(m, prometheus.MustNewConstMetric(mResSession, prometheus.GaugeValue, float64(s.Results.Session[0].Current), s.VDOM, "ipv4"))
-		m = append(m, prometheus.MustNewConstMetric(mResSession, prometheus.GaugeValue, float64(s.Results.Session6[0].Current), s.VDOM, "ipv6"))
+		results := decodeResources(s.Results, meta.Logger().With("vdom", s.VDOM))
+		if cpu := results["cpu"]; len(cpu) > 0 {
+			m = append(m, prometheus.MustNewConstMetric(mResCPU, prometheus.GaugeValue, float64(cpu[0].Current)/100.0, s.VDOM))
+		}
+		if mem := results["mem"]; len(mem) > 0 {
+			m = append(m, prometheus.MustNewConstMetric(mResMemory, prometheus.GaugeValue, float64(mem[0].Current)/100.0, s.VDOM))
+		}
+		if session := results["session"]; len(session) > 0 {
+			m = append(m, prometheus.MustNewConstMetric(mResSession, prometheus.GaugeValue, float64(session[0].Current), s.VDOM, "ipv4"))
+		}
+		if session6 := results["session6"]; len(session6) > 0 {
+			m = append(m, prometheus.MustNewConstMetric(mResSession, prometheus.GaugeValue, float64(session6[0].Current), s.VDOM, "ipv6"))
+		}
+		m = append(m, resourceMetrics(results, "fortigate_vdom_", interval, []string{"vdom"}, s.VDOM)...)
 	}
 	return m, true
 }
@@ -14,9 +14,11 @@
 package probe
 
 import (
+	"flag"
 	"strings"
 	"testing"
 
+	"github.com/prometheus-community/fortigate_exporter/internal/config"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
 )
@@ -25,6 +27,7 @@ func TestSystemResourceUsage(t *testing.T) {
 	c := newFakeClient()
 	c.prepare("api/v2/monitor/system/resource/usage", "testdata/usage.jsonnet")
 	r := prometheus.NewPedanticRegistry()
+	config.MustReInit()
 	if !testProbe(probeSystemResourceUsage, c, r) {
 		t.Errorf("probeSystemResourceUsage() returned non-success")
 	}
@@ -33,13 +36,67 @@ func TestSystemResourceUsage(t *testing.T) {
 	# HELP fortigate_cpu_usage_ratio Current resource usage ratio of system CPU, per core
 	# TYPE fortigate_cpu_usage_ratio gauge
 	fortigate_cpu_usage_ratio{processor="0"} 0.32
-	# HELP fortigate_memory_usage_ratio Current resource usage ratio of system memory
-	# TYPE fortigate_memory_usage_ratio gauge
-	fortigate_memory_usage_ratio 0.76
+	# HELP fortigate_cpu_usage_window_ratio Minimum, maximum and average of fortigate_cpu_usage_ratio over the resource usage interval
+	# TYPE fortigate_cpu_usage_window_ratio gauge
+	fortigate_cpu_usage_window_ratio{stat="average"} 0.008
+	fortigate_cpu_usage_window_ratio{stat="max"} 0.06
+	fortigate_cpu_usage_window_ratio{stat="min"} 0
 	# HELP fortigate_current_sessions Current amount of sessions, per IP version
 	# TYPE fortigate_current_sessions gauge
 	fortigate_current_sessions{protocol="ipv4"} 5
 	fortigate_current_sessions{protocol="ipv6"} 1
+	# HELP fortigate_current_sessions_window Minimum, maximum and average of fortigate_current_sessions over the resource usage interval
+	# TYPE fortigate_current_sessions_window gauge
+	fortigate_current_sessions_window{protocol="ipv4",stat="average"} 4.2
+	fortigate_current_sessions_window{protocol="ipv4",stat="max"} 5
+	fortigate_current_sessions_window{protocol="ipv4",stat="min"} 4
+	fortigate_current_sessions_window{protocol="ipv6",stat="average"} 2.4
+	fortigate_current_sessions_window{protocol="ipv6",stat="max"} 9
+	fortigate_current_sessions_window{protocol="ipv6",stat="min"} 0
+	# HELP fortigate_disk_usage_ratio Current resource usage ratio of the disk
+	# TYPE fortigate_disk_usage_ratio gauge
+	fortigate_disk_usage_ratio 0.01
+	# HELP fortigate_disk_usage_window_ratio Minimum, maximum and average of fortigate_disk_usage_ratio over the resource usage interval
+	# TYPE fortigate_disk_usage_window_ratio gauge
+	fortigate_disk_usage_window_ratio{stat="average"} 0.01
+	fortigate_disk_usage_window_ratio{stat="max"} 0.01
+	fortigate_disk_usage_window_ratio{stat="min"} 0.01
+	# HELP fortigate_log_rate Logs written per second, per destination
+	# TYPE fortigate_log_rate gauge
+	fortigate_log_rate{destination="disk"} 0
+	fortigate_log_rate{destination="fortianalyzer"} 0
+	fortigate_log_rate{destination="forticloud"} 0
+	# HELP fortigate_log_rate_window Minimum, maximum and average of fortigate_log_rate over the resource usage interval
+	# TYPE fortigate_log_rate_window gauge
+	fortigate_log_rate_window{destination="disk",stat="average"} 0
+	fortigate_log_rate_window{destination="disk",stat="max"} 0
+	fortigate_log_rate_window{destination="disk",stat="min"} 0
+	fortigate_log_rate_window{destination="fortianalyzer",stat="average"} 0
+	fortigate_log_rate_window{destination="fortianalyzer",stat="max"} 0
+	fortigate_log_rate_window{destination="fortianalyzer",stat="min"} 0
+	fortigate_log_rate_window{destination="forticloud",stat="average"} 0
+	fortigate_log_rate_window{destination="forticloud",stat="max"} 0
+	fortigate_log_rate_window{destination="forticloud",stat="min"} 0
+	# HELP fortigate_memory_usage_ratio Current resource usage ratio of system memory
+	# TYPE fortigate_memory_usage_ratio gauge
+	fortigate_memory_usage_ratio 0.76
+	# HELP fortigate_memory_usage_window_ratio Minimum, maximum and average of fortigate_memory_usage_ratio over the resource usage interval
+	# TYPE fortigate_memory_usage_window_ratio gauge
+	fortigate_memory_usage_window_ratio{stat="average"} 0.7590000000000001
+	fortigate_memory_usage_window_ratio{stat="max"} 0.76
+	fortigate_memory_usage_window_ratio{stat="min"} 0.75
+	# HELP fortigate_session_setup_rate Sessions set up per second, per IP version
+	# TYPE fortigate_session_setup_rate gauge
+	fortigate_session_setup_rate{protocol="ipv4"} 0
+	fortigate_session_setup_rate{protocol="ipv6"} 0
+	# HELP fortigate_session_setup_rate_window Minimum, maximum and average of fortigate_session_setup_rate over the resource usage interval
+	# TYPE fortigate_session_setup_rate_window gauge
+	fortigate_session_setup_rate_window{protocol="ipv4",stat="average"} 0.1
+	fortigate_session_setup_rate_window{protocol="ipv4",stat="max"} 2
+	fortigate_session_setup_rate_window{protocol="ipv4",stat="min"} 0
+	fortigate_session_setup_rate_window{protocol="ipv6",stat="average"} 0.2
+	fortigate_session_setup_rate_window{protocol="ipv6",stat="max"} 1
+	fortigate_session_setup_rate_window{protocol="ipv6",stat="min"} 0
 	`
 	if err := testutil.GatherAndCompare(r, strings.NewReader(em)); err != nil {
 		t.Fatalf("metric compare: err %v", err)
@@ -49,6 +106,7 @@ func TestSystemVDOMResources(t *testing.T) {
 	c := newFakeClient()
 	c.prepare("api/v2/monitor/system/resource/usage", "testdata/usage-vdom.jsonnet")
 	r := prometheus.NewPedanticRegistry()
+	config.MustReInit()
 	if !testProbe(probeSystemVDOMResources, c, r) {
 		t.Errorf("probeSystemVDOMResources() returned non-success")
 	}
@@ -58,16 +116,161 @@ func TestSystemVDOMResources(t *testing.T) {
 	# TYPE fortigate_vdom_cpu_usage_ratio gauge
 	fortigate_vdom_cpu_usage_ratio{vdom="FG-traffic"} 0
 	fortigate_vdom_cpu_usage_ratio{vdom="root"} 0.01
-	# HELP fortigate_vdom_memory_usage_ratio Current resource usage ratio of memory, per VDOM
-	# TYPE fortigate_vdom_memory_usage_ratio gauge
-	fortigate_vdom_memory_usage_ratio{vdom="FG-traffic"} 0
-	fortigate_vdom_memory_usage_ratio{vdom="root"} 0.78
+	# HELP fortigate_vdom_cpu_usage_window_ratio Minimum, maximum and average of fortigate_vdom_cpu_usage_ratio over the resource usage interval
+	# TYPE fortigate_vdom_cpu_usage_window_ratio gauge
+	fortigate_vdom_cpu_usage_window_ratio{stat="average",vdom="FG-traffic"} 0.001
+	fortigate_vdom_cpu_usage_window_ratio{stat="average",vdom="root"} 0.09300000000000001
+	fortigate_vdom_cpu_usage_window_ratio{stat="max",vdom="FG-traffic"} 0.02
+	fortigate_vdom_cpu_usage_window_ratio{stat="max",vdom="root"} 0.89
+	fortigate_vdom_cpu_usage_window_ratio{stat="min",vdom="FG-traffic"} 0
+	fortigate_vdom_cpu_usage_window_ratio{stat="min",vdom="root"} 0
 	# HELP fortigate_vdom_current_sessions Current amount of sessions, per VDOM and IP version
 	# TYPE fortigate_vdom_current_sessions gauge
 	fortigate_vdom_current_sessions{protocol="ipv4",vdom="FG-traffic"} 0
 	fortigate_vdom_current_sessions{protocol="ipv4",vdom="root"} 18
 	fortigate_vdom_current_sessions{protocol="ipv6",vdom="FG-traffic"} 7
 	fortigate_vdom_current_sessions{protocol="ipv6",vdom="root"} 7
+	# HELP fortigate_vdom_current_sessions_window Minimum, maximum and average of fortigate_vdom_current_sessions over the resource usage interval
+	# TYPE fortigate_vdom_current_sessions_window gauge
+	fortigate_vdom_current_sessions_window{protocol="ipv4",stat="average",vdom="FG-traffic"} 0
+	fortigate_vdom_current_sessions_window{protocol="ipv4",stat="average",vdom="root"} 16.05
+	fortigate_vdom_current_sessions_window{protocol="ipv4",stat="max",vdom="FG-traffic"} 0
+	fortigate_vdom_current_sessions_window{protocol="ipv4",stat="max",vdom="root"} 22
+	fortigate_vdom_current_sessions_window{protocol="ipv4",stat="min",vdom="FG-traffic"} 0
+	fortigate_vdom_current_sessions_window{protocol="ipv4",stat="min",vdom="root"} 11
+	fortigate_vdom_current_sessions_window{protocol="ipv6",stat="average",vdom="FG-traffic"} 5.45
+	fortigate_vdom_current_sessions_window{protocol="ipv6",stat="average",vdom="root"} 5.45
+	fortigate_vdom_current_sessions_window{protocol="ipv6",stat="max",vdom="FG-traffic"} 11
+	fortigate_vdom_current_sessions_window{protocol="ipv6",stat="max",vdom="root"} 11
+	fortigate_vdom_current_sessions_window{protocol="ipv6",stat="min",vdom="FG-traffic"} 2
+	fortigate_vdom_current_sessions_window{protocol="ipv6",stat="min",vdom="root"} 2
+	# HELP fortigate_vdom_disk_usage_ratio Current resource usage ratio of the disk, per VDOM
+	# TYPE fortigate_vdom_disk_usage_ratio gauge
+	fortigate_vdom_disk_usage_ratio{vdom="FG-traffic"} 0.01
+	fortigate_vdom_disk_usage_ratio{vdom="root"} 0.01
+	# HELP fortigate_vdom_disk_usage_window_ratio Minimum, maximum and average of fortigate_vdom_disk_usage_ratio over the resource usage interval
+	# TYPE fortigate_vdom_disk_usage_window_ratio gauge
+	fortigate_vdom_disk_usage_window_ratio{stat="average",vdom="FG-traffic"} 0.01
+	fortigate_vdom_disk_usage_window_ratio{stat="average",vdom="root"} 0.01
+	fortigate_vdom_disk_usage_window_ratio{stat="max",vdom="FG-traffic"} 0.01
+	fortigate_vdom_disk_usage_window_ratio{stat="max",vdom="root"} 0.01
+	fortigate_vdom_disk_usage_window_ratio{stat="min",vdom="FG-traffic"} 0.01
+	fortigate_vdom_disk_usage_window_ratio{stat="min",vdom="root"} 0.01
+	# HELP fortigate_vdom_log_rate Logs written per second, per destination, per VDOM
+	# TYPE fortigate_vdom_log_rate gauge
+	fortigate_vdom_log_rate{destination="disk",vdom="FG-traffic"} 0
+	fortigate_vdom_log_rate{destination="disk",vdom="root"} 0
+	fortigate_vdom_log_rate{destination="fortianalyzer",vdom="FG-traffic"} 0
+	fortigate_vdom_log_rate{destination="fortianalyzer",vdom="root"} 0
+	fortigate_vdom_log_rate{destination="forticloud",vdom="FG-traffic"} 0
+	fortigate_vdom_log_rate{destination="forticloud",vdom="root"} 0
+	# HELP fortigate_vdom_log_rate_window Minimum, maximum and average of fortigate_vdom_log_rate over the resource usage interval
+	# TYPE fortigate_vdom_log_rate_window gauge
+	fortigate_vdom_log_rate_window{destination="disk",stat="average",vdom="FG-traffic"} 0.1
+	fortigate_vdom_log_rate_window{destination="disk",stat="average",vdom="root"} 0.1
+	fortigate_vdom_log_rate_window{destination="disk",stat="max",vdom="FG-traffic"} 2
+	fortigate_vdom_log_rate_window{destination="disk",stat="max",vdom="root"} 2
+	fortigate_vdom_log_rate_window{destination="disk",stat="min",vdom="FG-traffic"} 0
+	fortigate_vdom_log_rate_window{destination="disk",stat="min",vdom="root"} 0
+	fortigate_vdom_log_rate_window{destination="fortianalyzer",stat="average",vdom="FG-traffic"} 0
+	fortigate_vdom_log_rate_window{destination="fortianalyzer",stat="average",vdom="root"} 0
+	fortigate_vdom_log_rate_window{destination="fortianalyzer",stat="max",vdom="FG-traffic"} 0
+	fortigate_vdom_log_rate_window{destination="fortianalyzer",stat="max",vdom="root"} 0
+	fortigate_vdom_log_rate_window{destination="fortianalyzer",stat="min",vdom="FG-traffic"} 0
+	fortigate_vdom_log_rate_window{destination="fortianalyzer",stat="min",vdom="root"} 0
+	fortigate_vdom_log_rate_window{destination="forticloud",stat="average",vdom="FG-traffic"} 0
+	fortigate_vdom_log_rate_window{destination="forticloud",stat="average",vdom="root"} 0
+	fortigate_vdom_log_rate_window{destination="forticloud",stat="max",vdom="FG-traffic"} 0
+	fortigate_vdom_log_rate_window{destination="forticloud",stat="max",vdom="root"} 0
+	fortigate_vdom_log_rate_window{destination="forticloud",stat="min",vdom="FG-traffic"} 0
+	fortigate_vdom_log_rate_window{destination="forticloud",stat="min",vdom="root"} 0
+	# HELP fortigate_vdom_memory_usage_ratio Current resource usage ratio of memory, per VDOM
+	# TYPE fortigate_vdom_memory_usage_ratio gauge
+	fortigate_vdom_memory_usage_ratio{vdom="FG-traffic"} 0
+	fortigate_vdom_memory_usage_ratio{vdom="root"} 0.78
+	# HELP fortigate_vdom_memory_usage_window_ratio Minimum, maximum and average of fortigate_vdom_memory_usage_ratio over the resource usage interval
+	# TYPE fortigate_vdom_memory_usage_window_ratio gauge
+	fortigate_vdom_memory_usage_window_ratio{stat="average",vdom="FG-traffic"} 0
+	fortigate_vdom_memory_usage_window_ratio{stat="average",vdom="root"} 0.785
+	fortigate_vdom_memory_usage_window_ratio{stat="max",vdom="FG-traffic"} 0
+	fortigate_vdom_memory_usage_window_ratio{stat="max",vdom="root"} 0.8
+	fortigate_vdom_memory_usage_window_ratio{stat="min",vdom="FG-traffic"} 0
+	fortigate_vdom_memory_usage_window_ratio{stat="min",vdom="root"} 0.78
+	# HELP fortigate_vdom_session_setup_rate Sessions set up per second, per IP version, per VDOM
+	# TYPE fortigate_vdom_session_setup_rate gauge
+	fortigate_vdom_session_setup_rate{protocol="ipv4",vdom="FG-traffic"} 0
+	fortigate_vdom_session_setup_rate{protocol="ipv4",vdom="root"} 1
+	fortigate_vdom_session_setup_rate{protocol="ipv6",vdom="FG-traffic"} 1
+	fortigate_vdom_session_setup_rate{protocol="ipv6",vdom="root"} 1
+	# HELP fortigate_vdom_session_setup_rate_window Minimum, maximum and average of fortigate_vdom_session_setup_rate over the resource usage interval
+	# TYPE fortigate_vdom_session_setup_rate_window gauge
+	fortigate_vdom_session_setup_rate_window{protocol="ipv4",stat="average",vdom="FG-traffic"} 0
+	fortigate_vdom_session_setup_rate_window{protocol="ipv4",stat="average",vdom="root"} 0.35
+	fortigate_vdom_session_setup_rate_window{protocol="ipv4",stat="max",vdom="FG-traffic"} 0
+	fortigate_vdom_session_setup_rate_window{protocol="ipv4",stat="max",vdom="root"} 2
+	fortigate_vdom_session_setup_rate_window{protocol="ipv4",stat="min",vdom="FG-traffic"} 0
+	fortigate_vdom_session_setup_rate_window{protocol="ipv4",stat="min",vdom="root"} 0
+	fortigate_vdom_session_setup_rate_window{protocol="ipv6",stat="average",vdom="FG-traffic"} 0.65
+	fortigate_vdom_session_setup_rate_window{protocol="ipv6",stat="average",vdom="root"} 0.65
+	fortigate_vdom_session_setup_rate_window{protocol="ipv6",stat="max",vdom="FG-traffic"} 6
+	fortigate_vdom_session_setup_rate_window{protocol="ipv6",stat="max",vdom="root"} 6
+	fortigate_vdom_session_setup_rate_window{protocol="ipv6",stat="min",vdom="FG-traffic"} 0
+	fortigate_vdom_session_setup_rate_window{protocol="ipv6",stat="min",vdom="root"} 0
+	`
+	if err := testutil.GatherAndCompare(r, strings.NewReader(em)); err != nil {
+		t.Fatalf("metric compare: err %v", err)
+	}
+}
+
+func TestSystemResourceUsageInterval(t *testing.T) {
+	c := newFakeClient()
+	c.prepare("api/v2/monitor/system/resource/usage?interval=10-min&scope=global", "testdata/usage-10-min.jsonnet")
+	r := prometheus.NewPedanticRegistry()
+	flag.Set("resource-usage-interval", "10-min")
+	defer flag.Set("resource-usage-interval", "1-min")
+	config.MustReInit()
+	if !testProbe(probeSystemResourceUsage, c, r) {
+		t.Errorf("probeSystemResourceUsage() returned non-success")
+	}
+
+	em := `
+	# HELP fortigate_cpu_usage_window_ratio Minimum, maximum and average of fortigate_cpu_usage_ratio over the resource usage interval
+	# TYPE fortigate_cpu_usage_window_ratio gauge
+	fortigate_cpu_usage_window_ratio{stat="average"} 0.2
+	fortigate_cpu_usage_window_ratio{stat="max"} 0.4
+	fortigate_cpu_usage_window_ratio{stat="min"} 0.08
+	# HELP fortigate_npu_sessions Current amount of sessions offloaded to the NPU, per IP version
+	# TYPE fortigate_npu_sessions gauge
+	fortigate_npu_sessions{protocol="ipv4"} 1200
+	# HELP fortigate_npu_sessions_window Minimum, maximum and average of fortigate_npu_sessions over the resource usage interval
+	# TYPE fortigate_npu_sessions_window gauge
+	fortigate_npu_sessions_window{protocol="ipv4",stat="average"} 1000
+	fortigate_npu_sessions_window{protocol="ipv4",stat="max"} 1200
+	fortigate_npu_sessions_window{protocol="ipv4",stat="min"} 800
+	# HELP fortigate_resource_usage Current value of a resource type without a dedicated metric, as returned by FortiOS
+	# TYPE fortigate_resource_usage gauge
+	fortigate_resource_usage{resource="sslvpn_tunnel"} 3
+	`
+	if err := testutil.GatherAndCompare(r, strings.NewReader(em)); err != nil {
+		t.Fatalf("metric compare: err %v", err)
+	}
+}
+
+func TestSystemResourceUsageMalformed(t *testing.T) {
+	c := newFakeClient()
+	c.prepare("api/v2/monitor/system/resource/usage?interval=1-hour&scope=global", "testdata/usage-malformed.jsonnet")
+	r := prometheus.NewPedanticRegistry()
+	flag.Set("resource-usage-interval", "1-hour")
+	defer flag.Set("resource-usage-interval", "1-min")
+	config.MustReInit()
+	if !testProbe(probeSystemResourceUsage, c, r) {
+		t.Errorf("probeSystemResourceUsage() returned non-success")
+	}
+
+	em := `
+	# HELP fortigate_memory_usage_ratio Current resource usage ratio of system memory
+	# TYPE fortigate_memory_usage_ratio gauge
+	fortigate_memory_usage_ratio 0.54
 	`
 	if err := testutil.GatherAndCompare(r, strings.NewReader(em)); err != nil {
 		t.Fatalf("metric compare: err %v", err)
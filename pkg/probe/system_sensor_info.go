@@ -14,7 +14,6 @@
 package probe
 
 import (
-	"log"
 	"reflect"
 
 	"github.com/prometheus-community/fortigate_exporter/pkg/http"
@@ -73,7 +72,7 @@ func probeSystemSensorInfo(c http.FortiHTTP, meta *TargetMetadata) ([]prometheus
 
 	var res SystemSensorInfo
 	if err := c.Get("api/v2/monitor/system/sensor-info", "vdom=root", &res); err != nil {
-		log.Printf("Warning: %v", err)
+		meta.Logger().Warn("API request failed", "err", err)
 		return nil, false
 	}
 
@@ -14,8 +14,6 @@
 package probe
 
 import (
-	"log"
-
 	"github.com/prometheus-community/fortigate_exporter/pkg/http"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -40,7 +38,7 @@ func probeSystemTime(c http.FortiHTTP, meta *TargetMetadata) ([]prometheus.Metri
 	var stime systemTime
 
 	if err := c.Get("api/v2/monitor/system/time", "vdom=root", &stime); err != nil {
-		log.Printf("Error: %v", err)
+		meta.Logger().Error("API request failed", "err", err)
 		return nil, false
 	}
 
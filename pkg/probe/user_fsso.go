@@ -14,7 +14,6 @@
 package probe
 
 import (
-	"log"
 	"strconv"
 
 	"github.com/prometheus-community/fortigate_exporter/pkg/http"
@@ -44,7 +43,7 @@ func probeUserFsso(c http.FortiHTTP, meta *TargetMetadata) ([]prometheus.Metric,
 
 	var res []UserFsso
 	if err := c.Get("api/v2/monitor/user/fsso", "vdom=*", &res); err != nil {
-		log.Printf("Error: %v", err)
+		meta.Logger().Error("API request failed", "err", err)
 		return nil, false
 	}
 
@@ -14,8 +14,6 @@
 package probe
 
 import (
-	"log"
-
 	"github.com/prometheus-community/fortigate_exporter/internal/config"
 	"github.com/prometheus-community/fortigate_exporter/pkg/http"
 	"github.com/prometheus/client_golang/prometheus"
@@ -49,7 +47,7 @@ func probeVPNSsl(c http.FortiHTTP, meta *TargetMetadata) ([]prometheus.Metric, b
 
 	var res []VPNUsers
 	if err := c.Get("api/v2/monitor/vpn/ssl", "vdom=*", &res); err != nil {
-		log.Printf("Error: %v", err)
+		meta.Logger().Error("API request failed", "err", err)
 		return nil, false
 	}
 
@@ -59,24 +57,21 @@ func probeVPNSsl(c http.FortiHTTP, meta *TargetMetadata) ([]prometheus.Metric, b
 
 		m = append(m, prometheus.MustNewConstMetric(vpncon, prometheus.GaugeValue, float64(count), r.VDOM))
 
+		// The number of users is capped by the series limit of the probe
 		if MaxVPNUsers != 0 {
-			if count > MaxVPNUsers {
-				log.Printf("Error: Received more VPN Users than maximum (%d > %d) allowed, ignoring metric ...", count, MaxVPNUsers)
-			} else {
-				// Structure for summarizing multi VPN per user
-				type VPNUserDesc struct {
-					VDOM     string
-					UserName string
-				}
-				userMap := map[VPNUserDesc]float64{}
+			// Structure for summarizing multi VPN per user
+			type VPNUserDesc struct {
+				VDOM     string
+				UserName string
+			}
+			userMap := map[VPNUserDesc]float64{}
 
-				for _, result := range r.Results {
-					userDesc := VPNUserDesc{r.VDOM, result.UserName}
-					userMap[userDesc]++
-				}
-				for userDesc, counter := range userMap {
-					m = append(m, prometheus.MustNewConstMetric(vpnusr, prometheus.GaugeValue, counter, userDesc.VDOM, userDesc.UserName))
-				}
+			for _, result := range r.Results {
+				userDesc := VPNUserDesc{r.VDOM, result.UserName}
+				userMap[userDesc]++
+			}
+			for userDesc, counter := range userMap {
+				m = append(m, prometheus.MustNewConstMetric(vpnusr, prometheus.GaugeValue, counter, userDesc.VDOM, userDesc.UserName))
 			}
 		}
 	}
@@ -14,11 +14,14 @@
 package probe
 
 import (
+	"context"
 	"flag"
+	"fmt"
 	"strings"
 	"testing"
 
 	"github.com/prometheus-community/fortigate_exporter/internal/config"
+	"github.com/prometheus-community/fortigate_exporter/internal/exportertest"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
 )
@@ -47,3 +50,34 @@ func TestVPNSsl(t *testing.T) {
 		t.Fatalf("metric compare: err %v", err)
 	}
 }
+
+func TestVPNSslSeriesLimit(t *testing.T) {
+	srv := exportertest.Serve(t, exportertest.Fixtures(t, "7.4"), "vpn-token")
+
+	tests := []struct {
+		name     string
+		maxUsers string
+		entry    string
+		success  bool
+	}{
+		// One series for the connections and one per user
+		{"within -max-vpn-users", "3", "", true},
+		{"above -max-vpn-users", "2", "", false},
+		{"series_limits override -max-vpn-users", "2", "\n  series_limits:\n    VPN/Ssl: 3", true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := fmt.Sprintf("%q:\n  token: vpn-token%s\n", srv.URL, tc.entry)
+			exportertest.Configure(t, auth, map[string]string{"max-vpn-users": tc.maxUsers})
+
+			pc := &ProbeCollector{Only: []string{"VPN/Ssl/Connections"}}
+			if _, err := pc.Probe(context.Background(), map[string]string{"target": srv.URL}, config.GetConfig()); err != nil {
+				t.Fatal(err)
+			}
+			res := pc.Results()
+			if len(res) != 1 || res[0].Success != tc.success {
+				t.Errorf("Probe() results %+v, expected success %v", res, tc.success)
+			}
+		})
+	}
+}
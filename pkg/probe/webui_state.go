@@ -14,8 +14,6 @@
 package probe
 
 import (
-	"log"
-
 	"github.com/prometheus-community/fortigate_exporter/pkg/http"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -46,7 +44,7 @@ func probeWebUIState(c http.FortiHTTP, meta *TargetMetadata) ([]prometheus.Metri
 	var state webuiState
 
 	if err := c.Get("api/v2/monitor/web-ui/state", "", &state); err != nil {
-		log.Printf("Error: %v", err)
+		meta.Logger().Error("API request failed", "err", err)
 		return nil, false
 	}
 
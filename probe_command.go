@@ -0,0 +1,112 @@
+// Copyright 2025 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/prometheus-community/fortigate_exporter/internal/config"
+	"github.com/prometheus-community/fortigate_exporter/pkg/probe"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+)
+
+// probeCommand runs the probes against a single target once, prints the
+// metrics in OpenMetrics format to stdout and a per-probe summary to stderr.
+// It returns the process exit code.
+func probeCommand(args []string, savedConfig config.FortiExporterConfig, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("probe", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	target := fs.String("target", "", "target to probe, e.g. https://my-fortigate")
+	probes := fs.String("probe", "", "comma-separated probe name prefixes to run instead of the include/exclude lists of the target")
+	token := fs.String("token", "", "API token to use instead of the one in the auth file")
+	profile := fs.String("profile", "", "auth file entry to take the probe include/exclude lists from, used with -token")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *target == "" {
+		fmt.Fprintln(stderr, "probe: -target is required")
+		fs.Usage()
+		return 2
+	}
+
+	params := map[string]string{"target": *target}
+	if *token != "" {
+		params["token"] = *token
+	}
+	if *profile != "" {
+		params["profile"] = *profile
+	}
+
+	pc := &probe.ProbeCollector{}
+	if *probes != "" {
+		pc.Only = strings.Split(*probes, ",")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(savedConfig.ScrapeTimeout)*time.Second)
+	defer cancel()
+	start := time.Now()
+	success, err := pc.Probe(ctx, params, savedConfig)
+	if err != nil {
+		fmt.Fprintf(stderr, "probe: %v\n", err)
+		return 2
+	}
+	duration := time.Since(start)
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(pc)
+	mfs, err := registry.Gather()
+	if err != nil {
+		fmt.Fprintf(stderr, "probe: failed to gather metrics: %v\n", err)
+		return 1
+	}
+	enc := expfmt.NewEncoder(stdout, expfmt.NewFormat(expfmt.TypeOpenMetrics))
+	for _, mf := range mfs {
+		if err := enc.Encode(mf); err != nil {
+			fmt.Fprintf(stderr, "probe: failed to encode metrics: %v\n", err)
+			return 1
+		}
+	}
+	if closer, ok := enc.(expfmt.Closer); ok {
+		closer.Close()
+	}
+
+	if results := pc.Results(); len(results) != 0 {
+		tw := tabwriter.NewWriter(stderr, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "PROBE\tRESULT\tDURATION\tMETRICS")
+		for _, r := range results {
+			result := "ok"
+			if r.Stale {
+				result = "FAILED (stale)"
+			} else if !r.Success {
+				result = "FAILED"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%d\n", r.Name, result, r.Duration.Round(time.Millisecond), r.Metrics)
+		}
+		tw.Flush()
+	}
+
+	if !success {
+		fmt.Fprintf(stderr, "Probe of %s failed after %s, see the log above for API errors\n", *target, duration.Round(time.Millisecond))
+		return 1
+	}
+	fmt.Fprintf(stderr, "Probe of %s succeeded in %s\n", *target, duration.Round(time.Millisecond))
+	return 0
+}
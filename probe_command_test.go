@@ -0,0 +1,114 @@
+// Copyright 2025 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus-community/fortigate_exporter/internal/config"
+	"github.com/prometheus-community/fortigate_exporter/internal/exportertest"
+)
+
+// newProbeCommandMock starts a mock FortiGate and configures the exporter
+// without an auth file entry for it, so the token is given with -token. The
+// fixtures of paths in missing are not served.
+func newProbeCommandMock(t *testing.T, missing ...string) *httptest.Server {
+	fs := exportertest.Fixtures(t, "7.4")
+	for _, path := range missing {
+		delete(fs, path)
+	}
+	srv := exportertest.Serve(t, fs, "probe-token")
+	exportertest.Configure(t, "probe-profile:\n  probes:\n    include:\n      - System/Status\n", nil)
+	return srv
+}
+
+func TestProbeCommand(t *testing.T) {
+	srv := newProbeCommandMock(t, "api/v2/monitor/license/status/select")
+
+	tests := []struct {
+		name        string
+		args        []string
+		code        int
+		stdout      []string
+		stderr      []string
+		emptyStdout bool
+	}{
+		{
+			name:   "success",
+			args:   []string{"-target", srv.URL, "-token", "probe-token", "-probe", "System/Status"},
+			code:   0,
+			stdout: []string{"# TYPE fortigate_version_info gauge", `fortigate_version_info{build="`, `fortigate_exporter_probe_success{probe="System/Status"} 1`, "# EOF"},
+			stderr: []string{"PROBE", "System/Status  ok", "succeeded"},
+		},
+		{
+			name:   "failed probe",
+			args:   []string{"-target", srv.URL, "-token", "probe-token", "-probe", "System/Status,License/Status"},
+			code:   1,
+			stdout: []string{`fortigate_exporter_probe_success{probe="License/Status"} 0`, "# EOF"},
+			stderr: []string{"License/Status  FAILED", "failed after"},
+		},
+		{
+			name:   "profile",
+			args:   []string{"-target", srv.URL, "-token", "probe-token", "-profile", "probe-profile"},
+			code:   0,
+			stdout: []string{`fortigate_exporter_probe_success{probe="System/Status"} 1`},
+			stderr: []string{"System/Status  ok", "succeeded"},
+		},
+		{
+			name:   "wrong token",
+			args:   []string{"-target", srv.URL, "-token", "wrong-token", "-probe", "System/Status"},
+			code:   1,
+			stderr: []string{"failed after"},
+		},
+		{
+			name:        "missing target",
+			args:        []string{"-probe", "System/Status"},
+			code:        2,
+			stderr:      []string{"-target is required", "Usage of probe"},
+			emptyStdout: true,
+		},
+		{
+			name:        "unknown flag",
+			args:        []string{"-target", srv.URL, "-bogus"},
+			code:        2,
+			stderr:      []string{"flag provided but not defined: -bogus"},
+			emptyStdout: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := probeCommand(tt.args, config.GetConfig(), &stdout, &stderr)
+			if code != tt.code {
+				t.Errorf("probeCommand() = %d, want %d, stderr:\n%s", code, tt.code, stderr.String())
+			}
+			for _, s := range tt.stdout {
+				if !strings.Contains(stdout.String(), s) {
+					t.Errorf("stdout does not contain %q:\n%s", s, stdout.String())
+				}
+			}
+			for _, s := range tt.stderr {
+				if !strings.Contains(stderr.String(), s) {
+					t.Errorf("stderr does not contain %q:\n%s", s, stderr.String())
+				}
+			}
+			if tt.emptyStdout && stdout.Len() != 0 {
+				t.Errorf("stdout = %q, want it empty", stdout.String())
+			}
+		})
+	}
+}